@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+// check fails when the puzzle has no solution or more than one
+func check(args []string) error {
+	flags := newFlagSet("check", "")
+	in := addInputFlags(flags)
+	rate := flags.Bool("rate", false, "also rate difficulty of the puzzle")
+	flags.Parse(args)
+
+	p, err := in.readPuzzle()
+	if err != nil {
+		return err
+	}
+
+	var s nonogram.Solver
+	solutions, err := s.CountSolutions(p.Rows, p.Columns, 2)
+	if err != nil {
+		return fmt.Errorf("failed to count solutions: %w", err)
+	}
+
+	switch len(solutions) {
+	case 0:
+		fmt.Println("no solution")
+		return errFailed
+	case 1:
+		fmt.Println("unique")
+	default:
+		fmt.Println("multiple, for example:")
+		for _, solution := range solutions {
+			fmt.Println(solution.StringCaged(5))
+		}
+		return errFailed
+	}
+
+	if *rate {
+		return printRating(p.Rows, p.Columns)
+	}
+
+	return nil
+}
+
+func printRating(rows, columns nonogram.FillPattern) error {
+	rating, err := nonogram.Rate(rows, columns)
+	if err != nil {
+		return fmt.Errorf("failed to rate the puzzle: %w", err)
+	}
+
+	fmt.Printf("tier: %s\n", rating.Tier)
+	fmt.Printf("score: %d\n", rating.Score)
+	fmt.Printf("technique: %s\n", rating.Technique)
+	fmt.Printf("passes: %d\n", rating.Passes)
+	fmt.Printf("intersections: %d\n", rating.Intersections)
+	fmt.Printf("probes: %d\n", rating.Probes)
+	fmt.Printf("guesses: %d\n", rating.Guesses)
+	fmt.Printf("backtracks: %d\n", rating.Backtracks)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+// convert turns png picture into a puzzle and prints it with clues
+func convert(args []string) error {
+	flags := newFlagSet("convert", " picture.png")
+	rows := flags.Int("rows", 0, "number of rows, 0 keeps the aspect ratio of the picture")
+	columns := flags.Int("columns", 20, "number of columns")
+	threshold := flags.Float64("threshold", 0, "luminance from 0 to 1 below which cells are filled, 0 means Otsu's threshold")
+	dither := flags.Bool("dither", false, "use Floyd-Steinberg dithering")
+	unique := flags.Bool("unique", false, "flip cells until the puzzle has a unique solution")
+	cage := flags.Int("cage", 5, "number of cells between cage lines, 0 means no cage")
+	output := flags.String("output", "", "write the puzzle to .non or .xml file")
+	timeout := flags.Duration("timeout", 0, "stop converting after this time, 0 means no timeout")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("%w: expected one picture", errUsage)
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open picture: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("failed to decode picture: %w", err)
+	}
+
+	n, m := *rows, *columns
+	if n <= 0 && m > 0 {
+		b := img.Bounds()
+		n = max(1, (m*b.Dy()+b.Dx()/2)/max(1, b.Dx()))
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	c := nonogram.ImageConverter{Threshold: *threshold, Dither: *dither, MakeUnique: *unique}
+	gram, isUnique, err := c.ConvertContext(ctx, img, n, m)
+	if err != nil {
+		return fmt.Errorf("failed to convert picture: %w", err)
+	}
+
+	fmt.Println(gram.StringWithClues(*cage))
+	if isUnique {
+		fmt.Println("unique")
+	} else {
+		fmt.Println("puzzle has more than one solution")
+	}
+
+	if *output != "" {
+		name := filepath.Base(flags.Arg(0))
+		p := nonogram.Puzzle{Title: strings.TrimSuffix(name, filepath.Ext(name))}
+		p.Rows, p.Columns = gram.FillPatterns()
+		if err := p.WriteFile(*output); err != nil {
+			return fmt.Errorf("failed to write puzzle: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+var symmetries = map[string]nonogram.Symmetry{
+	"none":       nonogram.NoSymmetry,
+	"horizontal": nonogram.HorizontalSymmetry,
+	"vertical":   nonogram.VerticalSymmetry,
+	"rotational": nonogram.RotationalSymmetry,
+}
+
+// generate prints the puzzle and writes its ID to stderr,
+// the same puzzle is generated again with -id
+func generate(args []string) error {
+	flags := newFlagSet("generate", "")
+	rows := flags.Int("rows", 10, "number of rows")
+	columns := flags.Int("columns", 10, "number of columns")
+	seed := flags.Int64("seed", 0, "seed of the random source, 0 means current time")
+	density := flags.Float64("density", 0.5, "probability of a cell to be filled in the initial grid")
+	symmetry := flags.String("symmetry", "none", "symmetry of the picture: none, horizontal, vertical or rotational")
+	difficulty := flags.Int("difficulty", 0, "minimal number of passes the line solver needs")
+	puzzleID := flags.String("id", "", "regenerate the puzzle with this ID, other generation flags are ignored")
+	out := addPuzzleFlags(flags, "non")
+	flags.Parse(args)
+
+	var gram *nonogram.Nonogram
+	var id nonogram.PuzzleID
+	var err error
+	if *puzzleID != "" {
+		id, err = nonogram.ParsePuzzleID(*puzzleID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		gram, err = nonogram.FromPuzzleID(id)
+	} else {
+		s, ok := symmetries[*symmetry]
+		if !ok {
+			return fmt.Errorf("%w: unknown symmetry '%s'", errUsage, *symmetry)
+		}
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+
+		g := nonogram.Generator{Seed: *seed, Density: *density, Symmetry: s, MinDifficulty: *difficulty}
+		gram, id, err = g.GenerateWithID(*rows, *columns)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate puzzle: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "id: %s\n", id)
+
+	p := nonogram.Puzzle{Title: id.String()}
+	p.Rows, p.Columns = gram.FillPatterns()
+
+	return out.write(&p)
+}
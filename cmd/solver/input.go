@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+// readers of puzzle formats by their names
+var readers = map[string]func(r io.Reader) (*nonogram.Puzzle, error){
+	"non":    nonogram.ReadNon,
+	"xml":    nonogram.ReadXML,
+	"g":      nonogram.ReadG,
+	"legacy": readLegacy,
+}
+
+type inputFlags struct {
+	path   string
+	format string
+}
+
+func addInputFlags(flags *flag.FlagSet) *inputFlags {
+	var in inputFlags
+	flags.StringVar(&in.path, "input", "-", "puzzle file, - reads the puzzle from stdin")
+	flags.StringVar(&in.format, "format", "auto", "input format: non, xml, g, legacy or auto to guess it by file extension and content")
+
+	return &in
+}
+
+// readPuzzle reads the puzzle and validates its clues,
+// validation problems are printed to stderr
+func (in *inputFlags) readPuzzle() (*nonogram.Puzzle, error) {
+	var data []byte
+	var err error
+	if in.path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(in.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read puzzle: %w", err)
+	}
+
+	format := in.format
+	if format == "auto" {
+		format = guessFormat(in.path, data)
+	}
+
+	read, ok := readers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown input format '%s'", errUsage, format)
+	}
+
+	p, err := read(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read puzzle: %w", err)
+	}
+
+	if err := nonogram.ValidatePuzzle(p.Rows, p.Columns); err != nil {
+		printValidationError(err)
+		return nil, errFailed
+	}
+
+	return p, nil
+}
+
+// guessFormat picks the format by file extension, when it's unknown
+// the format is guessed by the first character of the content
+func guessFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".non":
+		return "non"
+	case ".xml", ".pbn":
+		return "xml"
+	case ".g":
+		return "g"
+	}
+
+	content := bytes.TrimSpace(data)
+	switch {
+	case len(content) == 0:
+		return "legacy"
+	case content[0] == '<':
+		return "xml"
+	case content[0] == ':' || content[0] == '#':
+		return "g"
+	case content[0] >= '0' && content[0] <= '9':
+		return "legacy"
+	default:
+		return "non"
+	}
+}
+
+// printValidationError prints every problem on its own line
+// with one-based line numbers as they go in the input file
+func printValidationError(err error) {
+	var validationErr *nonogram.ValidationError
+	if !errors.As(err, &validationErr) {
+		fmt.Fprintf(os.Stderr, "invalid clues: %v\n", err)
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "invalid clues:")
+	for _, p := range validationErr.Problems {
+		if p.Index < 0 {
+			fmt.Fprintf(os.Stderr, "  %v\n", p.Reason)
+			continue
+		}
+
+		clue := make([]string, 0, len(p.Clue))
+		for _, size := range p.Clue {
+			clue = append(clue, strconv.Itoa(size))
+		}
+		fmt.Fprintf(os.Stderr, "  %s %d '%s': %v\n", p.Axis, p.Index+1, strings.Join(clue, " "), p.Reason)
+	}
+}
+
+// readLegacy reads puzzle in the format of input.txt:
+// size line, then a clue line for every row and every column
+func readLegacy(r io.Reader) (*nonogram.Puzzle, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() {
+		return nil, errors.New("nonogram size is not provided")
+	}
+
+	n, m, err := parseSize(sc.Text())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse nonogram size: %w", err)
+	}
+
+	rows := make(nonogram.FillPattern, n)
+	for i := range n {
+		if !sc.Scan() {
+			return nil, errors.New("not enough rows")
+		}
+
+		p, err := parsePatternLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s'", sc.Text())
+		}
+
+		rows[i] = p
+	}
+
+	columns := make(nonogram.FillPattern, m)
+	for i := range m {
+		if !sc.Scan() {
+			return nil, errors.New("not enough columns")
+		}
+
+		p, err := parsePatternLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s'", sc.Text())
+		}
+
+		columns[i] = p
+	}
+
+	return &nonogram.Puzzle{Rows: rows, Columns: columns}, nil
+}
+
+func parseSize(s string) (int, int, error) {
+	sizeStr := strings.Split(s, " ")
+	if len(sizeStr) != 2 {
+		return 0, 0, errors.New("expected two numbers N and M")
+	}
+
+	n, err := strconv.Atoi(sizeStr[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse '%s' as N", sizeStr[0])
+	}
+
+	m, err := strconv.Atoi(sizeStr[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse '%s' as M", sizeStr[1])
+	}
+
+	return n, m, nil
+}
+
+func parsePatternLine(s string) ([]int, error) {
+	strs := strings.Split(s, " ")
+
+	res := make([]int, 0)
+	for i := range strs {
+		x, err := strconv.Atoi(strs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, x)
+	}
+
+	return res, nil
+}
@@ -1,101 +1,97 @@
+// Solver is the command line app for nonograms:
+//
+//	solver <command> [flags]
+//
+// Run solver help to see the list of commands and solver <command> -h
+// to see flags of the command. The app exits with code 1 when the command
+// fails and with code 2 when the command line is wrong.
 package main
 
 import (
-	"bufio"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"os"
-	"strconv"
-	"strings"
+)
 
-	"github.com/Arzeeq/nonogram"
+var (
+	// errUsage is returned when the command line is wrong
+	errUsage = errors.New("invalid usage")
+	// errFailed is returned when the failure was already reported
+	errFailed = errors.New("command failed")
 )
 
-func main() {
-	file, err := os.Open("input.txt")
-	if err != nil {
-		log.Fatalf("failed to open input file: %v", err)
-	}
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	sc := bufio.NewScanner(file)
-	if !sc.Scan() {
-		log.Fatalf("nonogram size is not provided")
-	}
+var commands = []command{
+	{"solve", "solve the puzzle and print or draw the solution", solve},
+	{"generate", "generate a random uniquely solvable puzzle", generate},
+	{"check", "validate clues and check if the solution is unique", check},
+	{"render", "draw the unsolved puzzle or write it in another format", render},
+	{"convert", "turn png picture into a puzzle", convert},
+}
 
-	n, m, err := parseSize(sc.Text())
-	if err != nil {
-		log.Fatalf("failed to parse nonogram size: %v", err)
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	rows := make(nonogram.FillPattern, n)
-	for i := range n {
-		if !sc.Scan() {
-			log.Fatalf("not enought rows")
-		}
-
-		p, err := parsePatternLine(sc.Text())
-		if err != nil {
-			log.Fatalf("invalid pattern '%s'", sc.Text())
-		}
-
-		rows[i] = p
+	name := os.Args[1]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
 	}
 
-	columns := make(nonogram.FillPattern, m)
-	for i := range m {
-		if !sc.Scan() {
-			log.Fatalf("not enought rows")
+	for _, c := range commands {
+		if c.name == name {
+			os.Exit(exitCode(name, c.run(os.Args[2:])))
 		}
-
-		p, err := parsePatternLine(sc.Text())
-		if err != nil {
-			log.Fatalf("invalid pattern '%s'", sc.Text())
-		}
-
-		columns[i] = p
-	}
-
-	var s nonogram.Solver
-	if err := s.Solve(rows, columns); err != nil {
-		fmt.Println(err.Error())
 	}
 
-	fmt.Println(s.StringCaged(5))
-	s.SavePNG("solved.png", 10)
+	fmt.Fprintf(os.Stderr, "unknown command '%s'\n", name)
+	usage()
+	os.Exit(2)
 }
 
-func parseSize(s string) (int, int, error) {
-	sizeStr := strings.Split(s, " ")
-	if len(sizeStr) != 2 {
-		return 0, 0, errors.New("expected two numbers N and M")
-	}
-
-	n, err := strconv.Atoi(sizeStr[0])
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse '%s' as N", sizeStr[0])
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: solver <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintln(os.Stderr, "\nrun 'solver <command> -h' to see flags of the command")
+}
 
-	m, err := strconv.Atoi(sizeStr[1])
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse '%s' as M", sizeStr[1])
+// exitCode prints the error of the command and returns the code of the app
+func exitCode(name string, err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errFailed):
+		return 1
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "solver %s: %v\n", name, err)
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "solver %s: %v\n", name, err)
+		return 1
 	}
-
-	return n, m, nil
 }
 
-func parsePatternLine(s string) ([]int, error) {
-	strs := strings.Split(s, " ")
-
-	res := make([]int, 0)
-	for i := range strs {
-		x, err := strconv.Atoi(strs[i])
-		if err != nil {
-			return nil, err
-		}
-
-		res = append(res, x)
+// newFlagSet returns flags of the command, which exit with code 2
+// when they can't be parsed
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: solver %s [flags]%s\n", name, args)
+		flags.PrintDefaults()
 	}
 
-	return res, nil
+	return flags
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+type outputFlags struct {
+	cage   int
+	pretty bool
+	clues  bool
+	png    string
+	svg    string
+	scale  int
+}
+
+func addOutputFlags(flags *flag.FlagSet) *outputFlags {
+	var out outputFlags
+	flags.IntVar(&out.cage, "cage", 5, "number of cells between cage lines, 0 means no cage")
+	flags.BoolVar(&out.pretty, "pretty", false, "print cells with box drawing characters instead of ASCII")
+	flags.BoolVar(&out.clues, "clues", false, "print clues along the top and the left of the grid")
+	flags.StringVar(&out.png, "png", "", "save the grid to this png file")
+	flags.StringVar(&out.svg, "svg", "", "save the grid to this svg file")
+	flags.IntVar(&out.scale, "scale", 10, "width of a cell in pixels in png and svg files")
+
+	return &out
+}
+
+// grid returns the text of the grid
+func (out *outputFlags) grid(s *nonogram.Solver) string {
+	switch {
+	case out.clues && out.pretty:
+		return s.PrettyStringWithClues(out.cage)
+	case out.clues:
+		return s.StringWithClues(out.cage)
+	case out.pretty:
+		return s.PrettyStringCaged(out.cage)
+	default:
+		return s.StringCaged(out.cage)
+	}
+}
+
+// saveImages writes png and svg files when they are requested
+func (out *outputFlags) saveImages(s *nonogram.Solver) error {
+	if out.png != "" {
+		err := writeFile(out.png, func(f *os.File) error {
+			return s.EncodePNG(f, nonogram.PNGOptions{Scale: out.scale, Cage: out.cage})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save png: %w", err)
+		}
+	}
+
+	if out.svg != "" {
+		err := writeFile(out.svg, func(f *os.File) error {
+			return s.WriteSVG(f, nonogram.SVGOptions{Scale: out.scale, Cage: out.cage, Clues: out.clues})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save svg: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeFile creates file [name] and writes it with [write]
+func writeFile(name string, write func(f *os.File) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
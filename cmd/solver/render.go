@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+// writers of puzzle formats by their names
+var writers = map[string]func(p *nonogram.Puzzle, w io.Writer) error{
+	"non": (*nonogram.Puzzle).WriteNon,
+	"xml": (*nonogram.Puzzle).WriteXML,
+	"sheet": func(p *nonogram.Puzzle, w io.Writer) error {
+		_, err := io.WriteString(w, p.Sheet())
+		return err
+	},
+}
+
+type puzzleFlags struct {
+	output string
+	to     string
+	png    string
+	svg    string
+	scale  int
+}
+
+func addPuzzleFlags(flags *flag.FlagSet, to string) *puzzleFlags {
+	var out puzzleFlags
+	flags.StringVar(&out.output, "output", "", "write the puzzle to .non or .xml file instead of stdout")
+	flags.StringVar(&out.to, "to", to, "format of the puzzle printed to stdout: non, xml or sheet")
+	flags.StringVar(&out.png, "png", "", "draw the puzzle sheet to this png file")
+	flags.StringVar(&out.svg, "svg", "", "draw the puzzle sheet to this svg file")
+	flags.IntVar(&out.scale, "scale", 10, "width of a cell in pixels in png and svg files")
+
+	return &out
+}
+
+// write prints the puzzle or writes it to the files
+func (out *puzzleFlags) write(p *nonogram.Puzzle) error {
+	if out.output != "" {
+		if err := p.WriteFile(out.output); err != nil {
+			return fmt.Errorf("failed to write puzzle: %w", err)
+		}
+	} else {
+		write, ok := writers[out.to]
+		if !ok {
+			return fmt.Errorf("%w: unknown output format '%s'", errUsage, out.to)
+		}
+		if err := write(p, os.Stdout); err != nil {
+			return fmt.Errorf("failed to write puzzle: %w", err)
+		}
+	}
+
+	if out.png != "" {
+		if err := p.SaveSheetPNG(out.png, out.scale); err != nil {
+			return fmt.Errorf("failed to save png: %w", err)
+		}
+	}
+
+	if out.svg != "" {
+		err := writeFile(out.svg, func(f *os.File) error {
+			return p.WriteSheetSVG(f, out.scale)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save svg: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func render(args []string) error {
+	flags := newFlagSet("render", "")
+	in := addInputFlags(flags)
+	out := addPuzzleFlags(flags, "sheet")
+	flags.Parse(args)
+
+	p, err := in.readPuzzle()
+	if err != nil {
+		return err
+	}
+
+	return out.write(p)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Arzeeq/nonogram"
+)
+
+func solve(args []string) error {
+	flags := newFlagSet("solve", "")
+	in := addInputFlags(flags)
+	out := addOutputFlags(flags)
+	explain := flags.Bool("explain", false, "print every step of solving with the grid after it")
+	timeout := flags.Duration("timeout", 0, "stop solving after this time and print what was deduced, 0 means no timeout")
+	parallel := flags.Bool("parallel", false, "solve lines of one axis concurrently")
+	workers := flags.Int("workers", 0, "number of goroutines solving lines with -parallel, 0 means GOMAXPROCS")
+	flags.Parse(args)
+
+	p, err := in.readPuzzle()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var trace nonogram.Trace
+	s := nonogram.Solver{Parallel: *parallel, Workers: *workers}
+	if *explain {
+		s.OnStep = trace.Record
+	}
+
+	solveErr := s.SolveContext(ctx, p.Rows, p.Columns)
+	if *explain {
+		if err := explainSolving(p.Rows, p.Columns, trace, out); err != nil {
+			return err
+		}
+	}
+
+	var contradiction *nonogram.ContradictionError
+	if errors.As(solveErr, &contradiction) {
+		printContradiction(&s, contradiction)
+		return errFailed
+	}
+
+	fmt.Print(out.grid(&s))
+	if err := out.saveImages(&s); err != nil {
+		return err
+	}
+
+	// the partial grid is printed when solving was interrupted
+	return solveErr
+}
+
+func explainSolving(rows, columns nonogram.FillPattern, trace nonogram.Trace, out *outputFlags) error {
+	for i, step := range trace {
+		replayed, err := trace.Replay(rows, columns, i+1)
+		if err != nil {
+			return fmt.Errorf("failed to replay solving: %w", err)
+		}
+
+		fmt.Printf("step %d: %s\n", i+1, step)
+		fmt.Println(out.grid(replayed))
+	}
+
+	return nil
+}
+
+// printContradiction prints the partial grid where the line
+// with contradiction is marked with arrows
+func printContradiction(s *nonogram.Solver, err *nonogram.ContradictionError) {
+	fmt.Println(err.Error())
+
+	lines := strings.Split(strings.TrimSuffix(s.String(), "\n"), "\n")
+	for i, line := range lines {
+		if err.Axis == nonogram.Row && i == err.Index {
+			fmt.Printf("> %s <\n", line)
+		} else {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	if err.Axis == nonogram.Column {
+		fmt.Printf("  %s^\n", strings.Repeat(" ", err.Index))
+	}
+}
@@ -0,0 +1,203 @@
+package nonogram
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+)
+
+var ErrInvalidColor = errors.New("color is out of range")
+
+// MaxColors is the maximal number of colors in a colored nonogram
+// including the background
+const MaxColors = 64
+
+// colorRunes are used to print colors, the background is printed as '.'
+const colorRunes = "123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ@$%"
+
+// DefaultPalette is used to draw colored nonograms when no palette is set.
+// The first color is the background.
+var DefaultPalette = color.Palette{
+	color.RGBA{255, 255, 255, 255},
+	color.RGBA{0, 0, 0, 255},
+	color.RGBA{220, 30, 30, 255},
+	color.RGBA{30, 160, 50, 255},
+	color.RGBA{30, 70, 220, 255},
+	color.RGBA{240, 200, 20, 255},
+	color.RGBA{150, 60, 200, 255},
+	color.RGBA{20, 190, 200, 255},
+	color.RGBA{240, 130, 20, 255},
+	color.RGBA{130, 80, 40, 255},
+}
+
+// ColorBlock is a block of a colored clue. Color is an index
+// in the palette starting from 1, because 0 is the background.
+type ColorBlock struct {
+	Len   int
+	Color int
+}
+
+// ColorPattern is FillPattern of a colored nonogram.
+// Blocks of the same color have at least one blank cell between them,
+// while blocks of different colors may touch each other.
+type ColorPattern [][]ColorBlock
+
+// ColorNonogram is a grid where every cell holds
+// an index of its color, 0 is the background
+type ColorNonogram struct {
+	n, m int
+	grid []uint8
+}
+
+func NewColor(n, m int) *ColorNonogram {
+	return &ColorNonogram{n: n, m: m, grid: make([]uint8, n*m)}
+}
+
+func (n *ColorNonogram) Set(i, j, c int) {
+	if !(0 <= i && i < n.n) || !(0 <= j && j < n.m) || !(0 <= c && c < MaxColors) {
+		return
+	}
+
+	n.grid[i*n.m+j] = uint8(c)
+}
+
+func (n *ColorNonogram) Get(i, j int) int {
+	if !(0 <= i && i < n.n) || !(0 <= j && j < n.m) {
+		return 0
+	}
+
+	return int(n.grid[i*n.m+j])
+}
+
+func (n *ColorNonogram) String() string {
+	var b strings.Builder
+
+	for i := range n.n {
+		for j := range n.m {
+			b.WriteRune(colorRune(n.Get(i, j)))
+		}
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+// return rows and columns ColorPattern respectively
+func (n *ColorNonogram) FillPatterns() (ColorPattern, ColorPattern) {
+	rows := make(ColorPattern, n.n)
+	for i := range n.n {
+		rows[i] = colorLinePattern(n.m, func(k int) int { return n.Get(i, k) })
+	}
+
+	columns := make(ColorPattern, n.m)
+	for j := range n.m {
+		columns[j] = colorLinePattern(n.n, func(k int) int { return n.Get(k, j) })
+	}
+
+	return rows, columns
+}
+
+func colorLinePattern(length int, cell func(k int) int) []ColorBlock {
+	var res []ColorBlock
+	for k := range length {
+		c := cell(k)
+		if c == 0 {
+			continue
+		}
+
+		if k > 0 && cell(k-1) == c {
+			res[len(res)-1].Len++
+		} else {
+			res = append(res, ColorBlock{Len: 1, Color: c})
+		}
+	}
+
+	return res
+}
+
+// ValidateColorPuzzle checks that blocks of every line have valid color
+// and fit into the line and that rows and columns fill the same number
+// of cells of every color.
+// Returns *ValidationError with all the problems found.
+func ValidateColorPuzzle(rows ColorPattern, columns ColorPattern) error {
+	if len(rows) == 0 || len(columns) == 0 {
+		return toError([]*ClueError{{Index: -1, Reason: ErrInvalidSize}})
+	}
+
+	problems := rows.validate(Row, len(columns))
+	problems = append(problems, columns.validate(Column, len(rows))...)
+
+	if len(problems) == 0 && rows.filled() != columns.filled() {
+		problems = append(problems, &ClueError{Index: -1, Reason: ErrFillMismatch})
+	}
+
+	return toError(problems)
+}
+
+func (p ColorPattern) validate(axis Axis, length int) []*ClueError {
+	var problems []*ClueError
+	for i, clue := range p {
+		if reason := validateColorClue(clue, length); reason != nil {
+			lens := make([]int, 0, len(clue))
+			for _, block := range clue {
+				lens = append(lens, block.Len)
+			}
+			problems = append(problems, &ClueError{Axis: axis, Index: i, Clue: lens, Reason: reason})
+		}
+	}
+
+	return problems
+}
+
+func validateColorClue(clue []ColorBlock, length int) error {
+	// single zero block describes an empty line like in FillPattern
+	if len(clue) == 1 && clue[0].Len == 0 {
+		return nil
+	}
+
+	need := 0
+	for k, block := range clue {
+		if block.Len < 0 {
+			return ErrNegativeBlock
+		}
+		if block.Len == 0 {
+			return ErrMixedZeroBlock
+		}
+		if block.Color <= 0 || block.Color >= MaxColors {
+			return ErrInvalidColor
+		}
+
+		need += block.Len
+		if k > 0 && clue[k-1].Color == block.Color {
+			need++
+		}
+	}
+
+	if need > length {
+		return ErrLineOverflow
+	}
+
+	return nil
+}
+
+// filled returns number of filled cells of every color
+func (p ColorPattern) filled() [MaxColors]int {
+	var res [MaxColors]int
+	for _, clue := range p {
+		for _, block := range clue {
+			if 0 <= block.Color && block.Color < MaxColors {
+				res[block.Color] += block.Len
+			}
+		}
+	}
+
+	return res
+}
+
+func colorRune(c int) rune {
+	if c <= 0 || c > len(colorRunes) {
+		return '.'
+	}
+
+	return rune(colorRunes[c-1])
+}
@@ -0,0 +1,179 @@
+package nonogram_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorFillPatterns(t *testing.T) {
+	gram := nonogram.NewColor(2, 4)
+	gram.Set(0, 0, 1)
+	gram.Set(0, 1, 2)
+	gram.Set(0, 2, 2)
+	gram.Set(0, 3, 1)
+	gram.Set(1, 1, 2)
+	gram.Set(1, 3, 2)
+
+	require.Equal(t, "1221\n.2.2\n", gram.String())
+
+	rows, columns := gram.FillPatterns()
+	require.Equal(t, nonogram.ColorPattern{
+		{{Len: 1, Color: 1}, {Len: 2, Color: 2}, {Len: 1, Color: 1}},
+		{{Len: 1, Color: 2}, {Len: 1, Color: 2}},
+	}, rows)
+	require.Equal(t, nonogram.ColorPattern{
+		{{Len: 1, Color: 1}},
+		{{Len: 2, Color: 2}},
+		{{Len: 1, Color: 2}},
+		{{Len: 1, Color: 1}, {Len: 1, Color: 2}},
+	}, columns)
+}
+
+func TestColorSolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     nonogram.ColorPattern
+		columns  nonogram.ColorPattern
+		expected string
+	}{
+		{
+			name:     "touching blocks",
+			rows:     nonogram.ColorPattern{{{Len: 1, Color: 1}, {Len: 2, Color: 2}}},
+			columns:  nonogram.ColorPattern{{{Len: 1, Color: 1}}, {{Len: 1, Color: 2}}, {{Len: 1, Color: 2}}},
+			expected: "122\n",
+		},
+		{
+			name: "flag",
+			rows: nonogram.ColorPattern{
+				{{Len: 1, Color: 1}, {Len: 2, Color: 2}},
+				{{Len: 3, Color: 1}},
+				{{Len: 2, Color: 2}, {Len: 1, Color: 3}},
+			},
+			columns: nonogram.ColorPattern{
+				{{Len: 2, Color: 1}, {Len: 1, Color: 2}},
+				{{Len: 1, Color: 2}, {Len: 1, Color: 1}, {Len: 1, Color: 2}},
+				{{Len: 1, Color: 2}, {Len: 1, Color: 1}, {Len: 1, Color: 3}},
+			},
+			expected: "122\n111\n223\n",
+		},
+		{
+			name: "same color needs gap",
+			rows: nonogram.ColorPattern{
+				{{Len: 1, Color: 1}, {Len: 1, Color: 1}},
+				{{Len: 0}},
+			},
+			columns: nonogram.ColorPattern{
+				{{Len: 1, Color: 1}},
+				{{Len: 0}},
+				{{Len: 1, Color: 1}},
+			},
+			expected: "1.1\n...\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s nonogram.ColorSolver
+			require.NoError(t, s.Solve(tt.rows, tt.columns))
+			require.Equal(t, tt.expected, s.String())
+			require.Equal(t, tt.expected, s.ToNonogram().String())
+		})
+	}
+}
+
+func TestColorSolveRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 50 {
+		n, m := r.Intn(10)+1, r.Intn(10)+1
+		gram := nonogram.NewColor(n, m)
+		for i := range n {
+			for j := range m {
+				gram.Set(i, j, r.Intn(4))
+			}
+		}
+
+		rows, columns := gram.FillPatterns()
+		var s nonogram.ColorSolver
+		require.NoError(t, s.Solve(rows, columns))
+
+		solvedRows, solvedColumns := s.ToNonogram().FillPatterns()
+		require.Equal(t, rows, solvedRows)
+		require.Equal(t, columns, solvedColumns)
+	}
+}
+
+func TestColorSolveErrors(t *testing.T) {
+	var s nonogram.ColorSolver
+
+	err := s.Solve(nonogram.ColorPattern{{{Len: 1, Color: 1}, {Len: 1, Color: 1}}}, nonogram.ColorPattern{{{Len: 1, Color: 1}}})
+	var validationErr *nonogram.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.ErrorIs(t, err, nonogram.ErrLineOverflow)
+
+	err = s.Solve(nonogram.ColorPattern{{{Len: 1, Color: nonogram.MaxColors}}}, nonogram.ColorPattern{{{Len: 1, Color: 1}}})
+	require.ErrorIs(t, err, nonogram.ErrInvalidColor)
+
+	err = s.Solve(
+		nonogram.ColorPattern{{{Len: 1, Color: 1}}, {{Len: 1, Color: 2}}},
+		nonogram.ColorPattern{{{Len: 1, Color: 1}}, {{Len: 1, Color: 1}}},
+	)
+	require.ErrorIs(t, err, nonogram.ErrFillMismatch)
+
+	err = s.Solve(
+		nonogram.ColorPattern{{{Len: 1, Color: 1}, {Len: 1, Color: 2}}, {{Len: 1, Color: 2}, {Len: 1, Color: 1}}},
+		nonogram.ColorPattern{{{Len: 1, Color: 2}, {Len: 1, Color: 1}}, {{Len: 1, Color: 1}, {Len: 1, Color: 2}}},
+	)
+	require.ErrorIs(t, err, nonogram.ErrContradiction)
+}
+
+func TestColorSavePNG(t *testing.T) {
+	s := nonogram.ColorSolver{Palette: color.Palette{color.White, color.Black, color.RGBA{255, 0, 0, 255}}}
+	require.NoError(t, s.Solve(
+		nonogram.ColorPattern{{{Len: 1, Color: 1}, {Len: 1, Color: 2}}},
+		nonogram.ColorPattern{{{Len: 1, Color: 1}}, {{Len: 1, Color: 2}}, {{Len: 0}}},
+	))
+
+	name := filepath.Join(t.TempDir(), "solved.png")
+	require.NoError(t, s.SavePNG(name, 2))
+
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	require.Equal(t, 6, img.Bounds().Dx())
+	require.Equal(t, 2, img.Bounds().Dy())
+
+	require.Equal(t, color.RGBAModel.Convert(color.Black), color.RGBAModel.Convert(img.At(1, 1)))
+	require.Equal(t, color.RGBA{255, 0, 0, 255}, color.RGBAModel.Convert(img.At(2, 0)))
+	require.Equal(t, color.RGBAModel.Convert(color.White), color.RGBAModel.Convert(img.At(5, 1)))
+}
+
+func TestColorEncodePNG(t *testing.T) {
+	s := nonogram.ColorSolver{Palette: color.Palette{color.White, color.RGBA{255, 0, 0, 255}}}
+	require.NoError(t, s.Solve(
+		nonogram.ColorPattern{{{Len: 1, Color: 1}}, {{Len: 0}}},
+		nonogram.ColorPattern{{{Len: 1, Color: 1}}, {{Len: 0}}},
+	))
+
+	var b bytes.Buffer
+	require.NoError(t, s.EncodePNG(&b, nonogram.PNGOptions{Scale: 3, GridLines: true}))
+
+	img, err := png.Decode(&b)
+	require.NoError(t, err)
+	require.Equal(t, 7, img.Bounds().Dx())
+	require.Equal(t, 7, img.Bounds().Dy())
+	require.Equal(t, color.RGBA{200, 200, 200, 255}, color.RGBAModel.Convert(img.At(0, 0)))
+	require.Equal(t, color.RGBA{255, 0, 0, 255}, color.RGBAModel.Convert(img.At(1, 1)))
+	require.Equal(t, color.RGBAModel.Convert(color.White), color.RGBAModel.Convert(img.At(4, 4)))
+}
@@ -0,0 +1,414 @@
+package nonogram
+
+import (
+	"image/color"
+	"image/png"
+	"io"
+	"math/bits"
+	"strings"
+)
+
+// colorLineSolver is lineSolver for lines of a colored nonogram.
+// Every cell is a bit mask of colors the cell may have, bit 0 is
+// the background. Blocks of different colors may touch each other,
+// so the blank cell after a block is required only when the next
+// block has the same color.
+type colorLineSolver struct {
+	clue []ColorBlock
+	line []uint64
+	n    int
+	// bad[c][i] is a count of cells in line[:i] that can't have color c
+	bad map[int][]int
+	// fwd[i][j] reports if first j blocks can be placed in line[:i]
+	// so that j-th block may start at cell i
+	fwd [][]bool
+	// bwd[i][j] reports if blocks from j-th to the last one
+	// can be placed in line[i:]
+	bwd [][]bool
+}
+
+// solveColorLine returns [line] where every cell keeps only colors
+// it has in some placement of [clue].
+// It returns ErrContradiction if [clue] can't be placed in [line].
+func solveColorLine(clue []ColorBlock, line []uint64) ([]uint64, error) {
+	ls := newColorLineSolver(clue, line)
+	if !ls.bwd[0][0] {
+		return nil, ErrContradiction
+	}
+
+	k := len(ls.clue)
+	res := make([]uint64, ls.n)
+	for i := range ls.n {
+		if ls.line[i]&1 == 0 {
+			continue
+		}
+		for j := range k + 1 {
+			if ls.fwd[i][j] && ls.bwd[i+1][j] {
+				res[i] |= 1
+				break
+			}
+		}
+	}
+
+	for j, block := range ls.clue {
+		// difference array, cells covered by some placement of the block
+		cover := make([]int, ls.n+1)
+		for start := 0; start+block.Len <= ls.n; start++ {
+			if !ls.fwd[start][j] {
+				continue
+			}
+			next, ok := ls.next(j, start)
+			if !ok || !ls.bwd[next][j+1] {
+				continue
+			}
+
+			cover[start]++
+			cover[start+block.Len]--
+			if next > start+block.Len {
+				res[start+block.Len] |= 1
+			}
+		}
+
+		blocks := 0
+		for i := range ls.n {
+			blocks += cover[i]
+			if blocks > 0 {
+				res[i] |= 1 << block.Color
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func newColorLineSolver(clue []ColorBlock, line []uint64) *colorLineSolver {
+	ls := colorLineSolver{
+		n:    len(line),
+		line: line,
+		bad:  make(map[int][]int),
+	}
+
+	for _, block := range clue {
+		if block.Len > 0 {
+			ls.clue = append(ls.clue, block)
+		}
+	}
+
+	for _, block := range ls.clue {
+		if _, ok := ls.bad[block.Color]; ok {
+			continue
+		}
+
+		bad := make([]int, ls.n+1)
+		for i := range ls.n {
+			bad[i+1] = bad[i]
+			if ls.line[i]&(1<<block.Color) == 0 {
+				bad[i+1]++
+			}
+		}
+		ls.bad[block.Color] = bad
+	}
+
+	k := len(ls.clue)
+	ls.fwd = make([][]bool, ls.n+1)
+	ls.bwd = make([][]bool, ls.n+1)
+	for i := range ls.n + 1 {
+		ls.fwd[i] = make([]bool, k+1)
+		ls.bwd[i] = make([]bool, k+1)
+	}
+
+	ls.fwd[0][0] = true
+	for i := range ls.n {
+		for j := range k + 1 {
+			if !ls.fwd[i][j] {
+				continue
+			}
+			if ls.line[i]&1 != 0 {
+				ls.fwd[i+1][j] = true
+			}
+			if j < k {
+				if next, ok := ls.next(j, i); ok {
+					ls.fwd[next][j+1] = true
+				}
+			}
+		}
+	}
+
+	ls.bwd[ls.n][k] = true
+	for i := ls.n - 1; i >= 0; i-- {
+		for j := range k + 1 {
+			if ls.line[i]&1 != 0 && ls.bwd[i+1][j] {
+				ls.bwd[i][j] = true
+			} else if j < k {
+				next, ok := ls.next(j, i)
+				ls.bwd[i][j] = ok && ls.bwd[next][j+1]
+			}
+		}
+	}
+
+	return &ls
+}
+
+// next returns the cell where the block after j-th one may start
+// when j-th block starts at cell [start], and reports if j-th block
+// can start there at all
+func (ls *colorLineSolver) next(j, start int) (int, bool) {
+	block := ls.clue[j]
+	end := start + block.Len
+	if end > ls.n {
+		return 0, false
+	}
+
+	bad := ls.bad[block.Color]
+	if bad[end]-bad[start] != 0 {
+		return 0, false
+	}
+
+	if j+1 < len(ls.clue) && ls.clue[j+1].Color == block.Color {
+		if end == ls.n || ls.line[end]&1 == 0 {
+			return 0, false
+		}
+		return end + 1, true
+	}
+
+	return end, true
+}
+
+// ColorSolver solves colored nonograms the same way as Solver does:
+// it propagates line deductions and guesses when they get stuck.
+type ColorSolver struct {
+	n, m int
+	// bit mask of colors every cell may have, bit 0 is the background
+	grid    [][]uint64
+	rows    ColorPattern
+	columns ColorPattern
+
+	// Palette used by SavePNG and EncodePNG,
+	// DefaultPalette is used when it's nil
+	Palette color.Palette
+}
+
+func (s *ColorSolver) Solve(rows ColorPattern, columns ColorPattern) error {
+	if rows == nil || columns == nil {
+		return ErrNilPattern
+	}
+
+	if err := ValidateColorPuzzle(rows, columns); err != nil {
+		return err
+	}
+
+	var used uint64 = 1
+	for _, clue := range rows {
+		for _, block := range clue {
+			used |= 1 << block.Color
+		}
+	}
+
+	s.n = len(rows)
+	s.m = len(columns)
+	s.rows = rows
+	s.columns = columns
+	s.grid = make([][]uint64, s.n)
+	for i := range s.n {
+		s.grid[i] = make([]uint64, s.m)
+		for j := range s.m {
+			s.grid[i][j] = used
+		}
+	}
+
+	return s.solve()
+}
+
+func (s *ColorSolver) solve() error {
+	if err := s.propagate(); err != nil {
+		return err
+	}
+
+	if s.isSolved() {
+		return nil
+	}
+
+	return s.search()
+}
+
+// propagate solves rows and columns one by one
+// until no more colors can be ruled out
+func (s *ColorSolver) propagate() error {
+	for {
+		rowChanges, err := s.tryLines(Row)
+		if err != nil {
+			return err
+		}
+
+		columnChanges, err := s.tryLines(Column)
+		if err != nil {
+			return err
+		}
+
+		if rowChanges == 0 && columnChanges == 0 {
+			return nil
+		}
+	}
+}
+
+// returns count of changes done and error if contradiction is found
+func (s *ColorSolver) tryLines(axis Axis) (int, error) {
+	changesCount := 0
+
+	clues, count, length := s.rows, s.n, s.m
+	cell := func(index, k int) *uint64 { return &s.grid[index][k] }
+	if axis == Column {
+		clues, count, length = s.columns, s.m, s.n
+		cell = func(index, k int) *uint64 { return &s.grid[k][index] }
+	}
+
+	line := make([]uint64, length)
+	for index := range count {
+		for k := range length {
+			line[k] = *cell(index, k)
+		}
+
+		solved, err := solveColorLine(clues[index], line)
+		if err != nil {
+			return 0, err
+		}
+
+		for k := range length {
+			if solved[k] != line[k] {
+				*cell(index, k) = solved[k]
+				changesCount++
+			}
+		}
+	}
+
+	return changesCount, nil
+}
+
+// search guesses a color of the cell with the least number
+// of possible colors and solves the rest recursively
+func (s *ColorSolver) search() error {
+	bestRow, bestColumn, bestCount := -1, -1, MaxColors+1
+	for i := range s.n {
+		for j := range s.m {
+			count := bits.OnesCount64(s.grid[i][j])
+			if count > 1 && count < bestCount {
+				bestRow, bestColumn, bestCount = i, j, count
+			}
+		}
+	}
+
+	colors := s.grid[bestRow][bestColumn]
+	for colors != 0 {
+		c := bits.TrailingZeros64(colors)
+		colors &= colors - 1
+
+		branch := s.copy()
+		branch.grid[bestRow][bestColumn] = 1 << c
+		if err := branch.solve(); err != nil {
+			continue
+		}
+
+		s.grid = branch.grid
+		return nil
+	}
+
+	return ErrContradiction
+}
+
+func (s *ColorSolver) isSolved() bool {
+	for i := range s.n {
+		for j := range s.m {
+			if bits.OnesCount64(s.grid[i][j]) != 1 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// String shows the background as '.', colors as '1'...'9', 'a'...'z', etc.
+// and cells which color is not known yet as '?'
+func (s *ColorSolver) String() string {
+	var b strings.Builder
+
+	for i := range s.n {
+		for j := range s.m {
+			if c, ok := s.color(i, j); ok {
+				b.WriteRune(colorRune(c))
+			} else {
+				b.WriteRune('?')
+			}
+		}
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+// When solver saves png, it paints cells in colors from the palette
+// and in gray if the color of a cell is not known
+func (s *ColorSolver) SavePNG(name string, scale int) error {
+	return savePNG(name, func(w io.Writer) error {
+		return s.EncodePNG(w, PNGOptions{Scale: scale})
+	})
+}
+
+// EncodePNG writes the grid to [w] as png image drawn with [opts].
+// Cells are painted with Palette like in SavePNG, opts.Palette is not used.
+func (s *ColorSolver) EncodePNG(w io.Writer, opts PNGOptions) error {
+	palette := s.Palette
+	if palette == nil {
+		palette = DefaultPalette
+	}
+
+	img := drawCells(s.n, s.m, func(i, j int) color.Color {
+		if idx, ok := s.color(i, j); ok && idx < len(palette) {
+			return palette[idx]
+		}
+		return color.RGBA{128, 128, 128, 255}
+	}, opts)
+
+	return png.Encode(w, img)
+}
+
+func (s *ColorSolver) ToNonogram() *ColorNonogram {
+	nono := NewColor(s.n, s.m)
+
+	for i := 0; i < s.n; i++ {
+		for j := 0; j < s.m; j++ {
+			if c, ok := s.color(i, j); ok {
+				nono.Set(i, j, c)
+			}
+		}
+	}
+
+	return nono
+}
+
+// color returns color of the cell if it's known
+func (s *ColorSolver) color(i, j int) (int, bool) {
+	if bits.OnesCount64(s.grid[i][j]) != 1 {
+		return 0, false
+	}
+
+	return bits.TrailingZeros64(s.grid[i][j]), true
+}
+
+func (s *ColorSolver) copy() *ColorSolver {
+	newSolver := ColorSolver{
+		n:       s.n,
+		m:       s.m,
+		rows:    s.rows,
+		columns: s.columns,
+		grid:    make([][]uint64, s.n),
+		Palette: s.Palette,
+	}
+
+	for i := range s.n {
+		newSolver.grid[i] = make([]uint64, s.m)
+		copy(newSolver.grid[i], s.grid[i])
+	}
+
+	return &newSolver
+}
@@ -0,0 +1,467 @@
+package nonogram
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFormat = errors.New("invalid puzzle format")
+var ErrUnknownFormat = errors.New("unknown puzzle format")
+
+// Puzzle is a puzzle read from a file together with its metadata
+type Puzzle struct {
+	Title     string
+	Author    string
+	Copyright string
+	Rows      FillPattern
+	Columns   FillPattern
+}
+
+// ReadFile reads the puzzle in the format given by the file extension:
+// .non, .xml or .g. Returns ErrUnknownFormat for other extensions.
+func ReadFile(name string) (*Puzzle, error) {
+	var read func(r io.Reader) (*Puzzle, error)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".non":
+		read = ReadNon
+	case ".xml", ".pbn":
+		read = ReadXML
+	case ".g":
+		read = ReadG
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return read(f)
+}
+
+// ReadNon reads the puzzle in Simon Tatham's .non format:
+//
+//	title "Demo"
+//	by "Author"
+//	width 3
+//	height 2
+//
+//	rows
+//	1,1
+//	2
+//
+//	columns
+//	1
+//	1
+//	2
+//
+// Clues are separated by commas, an empty line or 0 is an empty clue.
+// Unknown keywords are skipped.
+func ReadNon(r io.Reader) (*Puzzle, error) {
+	var p Puzzle
+	width, height := -1, -1
+
+	sc := bufio.NewScanner(r)
+	lineNumber := 0
+	next := func() (string, bool) {
+		if !sc.Scan() {
+			return "", false
+		}
+		lineNumber++
+		return strings.TrimSpace(sc.Text()), true
+	}
+
+	// readClues reads [count] clue lines of a rows or columns section
+	readClues := func(count int) (FillPattern, error) {
+		if count < 0 {
+			return nil, formatError(lineNumber, "size must be set before clues")
+		}
+
+		clues := make(FillPattern, count)
+		for i := range count {
+			line, ok := next()
+			if !ok {
+				return nil, formatError(lineNumber, "expected %d clues, got %d", count, i)
+			}
+
+			clue, err := parseClue(strings.ReplaceAll(line, ",", " "))
+			if err != nil {
+				return nil, formatError(lineNumber, "%v", err)
+			}
+			clues[i] = clue
+		}
+
+		return clues, nil
+	}
+
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value, _ := strings.Cut(line, " ")
+		value = unquote(value)
+
+		var err error
+		switch strings.ToLower(keyword) {
+		case "title":
+			p.Title = value
+		case "by", "author":
+			p.Author = value
+		case "copyright":
+			p.Copyright = value
+		case "width":
+			width, err = strconv.Atoi(value)
+		case "height":
+			height, err = strconv.Atoi(value)
+		case "rows":
+			p.Rows, err = readClues(height)
+		case "columns":
+			p.Columns, err = readClues(width)
+		}
+
+		if err != nil {
+			var numErr *strconv.NumError
+			if errors.As(err, &numErr) {
+				return nil, formatError(lineNumber, "invalid %s '%s'", keyword, value)
+			}
+			return nil, err
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.complete()
+}
+
+// ReadG reads the puzzle in Olsak's .g format: a ': rows' line,
+// one clue per line with space separated blocks, then the same
+// for ': columns'. Lines starting with '#' are comments.
+// Colored puzzles are not supported.
+func ReadG(r io.Reader) (*Puzzle, error) {
+	var p Puzzle
+	var section *FillPattern
+
+	sc := bufio.NewScanner(r)
+	lineNumber := 0
+	for sc.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(sc.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(line, ":"); ok {
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "rows":
+				section = &p.Rows
+			case "columns":
+				section = &p.Columns
+			default:
+				return nil, formatError(lineNumber, "unknown section '%s'", line)
+			}
+			continue
+		}
+
+		if section == nil {
+			return nil, formatError(lineNumber, "clue outside of rows and columns sections")
+		}
+
+		clue, err := parseClue(line)
+		if err != nil {
+			return nil, formatError(lineNumber, "%v", err)
+		}
+		*section = append(*section, clue)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.complete()
+}
+
+// webpbn XML elements
+type xmlPuzzleSet struct {
+	XMLName xml.Name    `xml:"puzzleset"`
+	Puzzles []xmlPuzzle `xml:"puzzle"`
+}
+
+type xmlPuzzle struct {
+	Type         string     `xml:"type,attr,omitempty"`
+	DefaultColor string     `xml:"defaultcolor,attr,omitempty"`
+	Title        string     `xml:"title,omitempty"`
+	Author       string     `xml:"author,omitempty"`
+	Copyright    string     `xml:"copyright,omitempty"`
+	Colors       []xmlColor `xml:"color"`
+	Clues        []xmlClues `xml:"clues"`
+}
+
+type xmlColor struct {
+	Name  string `xml:"name,attr"`
+	Char  string `xml:"char,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xmlClues struct {
+	Type  string    `xml:"type,attr"`
+	Lines []xmlLine `xml:"line"`
+}
+
+type xmlLine struct {
+	Counts []xmlCount `xml:"count"`
+}
+
+type xmlCount struct {
+	Color string `xml:"color,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// ReadXML reads the first puzzle of webpbn XML export.
+// Colored puzzles are not supported.
+func ReadXML(r io.Reader) (*Puzzle, error) {
+	var root struct {
+		XMLName xml.Name
+		xmlPuzzle
+		Puzzles []xmlPuzzle `xml:"puzzle"`
+	}
+
+	d := xml.NewDecoder(r)
+	d.Entity = xml.HTMLEntity
+	if err := d.Decode(&root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	// puzzle may be the root element or be inside of puzzleset
+	x := root.xmlPuzzle
+	if root.XMLName.Local != "puzzle" {
+		if len(root.Puzzles) == 0 {
+			return nil, fmt.Errorf("%w: no puzzle found", ErrInvalidFormat)
+		}
+		x = root.Puzzles[0]
+	}
+
+	defaultColor := x.DefaultColor
+	if defaultColor == "" {
+		defaultColor = "black"
+	}
+
+	p := Puzzle{
+		Title:     strings.TrimSpace(x.Title),
+		Author:    strings.TrimSpace(x.Author),
+		Copyright: strings.TrimSpace(x.Copyright),
+	}
+
+	for _, clues := range x.Clues {
+		pattern := make(FillPattern, 0, len(clues.Lines))
+		for _, line := range clues.Lines {
+			clue := []int{}
+			for _, count := range line.Counts {
+				if count.Color != "" && count.Color != defaultColor {
+					return nil, fmt.Errorf("%w: colored puzzles are not supported", ErrInvalidFormat)
+				}
+
+				size, err := strconv.Atoi(strings.TrimSpace(count.Value))
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid count '%s'", ErrInvalidFormat, count.Value)
+				}
+				clue = append(clue, size)
+			}
+
+			if len(clue) == 0 {
+				clue = append(clue, 0)
+			}
+			pattern = append(pattern, clue)
+		}
+
+		switch clues.Type {
+		case "rows":
+			p.Rows = pattern
+		case "columns":
+			p.Columns = pattern
+		default:
+			return nil, fmt.Errorf("%w: unknown clues type '%s'", ErrInvalidFormat, clues.Type)
+		}
+	}
+
+	return p.complete()
+}
+
+// WriteFile writes the puzzle in the format given by the file extension:
+// .non or .xml. Returns ErrUnknownFormat for other extensions.
+func (p *Puzzle) WriteFile(name string) error {
+	var write func(w io.Writer) error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".non":
+		write = p.WriteNon
+	case ".xml", ".pbn":
+		write = p.WriteXML
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// WriteNon writes the puzzle in .non format, which is read by ReadNon
+func (p *Puzzle) WriteNon(w io.Writer) error {
+	var b strings.Builder
+
+	for _, field := range []struct{ keyword, value string }{
+		{"title", p.Title},
+		{"by", p.Author},
+		{"copyright", p.Copyright},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s %s\n", field.keyword, strconv.Quote(field.value))
+		}
+	}
+
+	fmt.Fprintf(&b, "width %d\nheight %d\n", len(p.Columns), len(p.Rows))
+
+	for _, section := range []struct {
+		name  string
+		clues FillPattern
+	}{
+		{"rows", p.Rows},
+		{"columns", p.Columns},
+	} {
+		fmt.Fprintf(&b, "\n%s\n", section.name)
+		for _, clue := range section.clues {
+			b.WriteString(joinClue(clue, ","))
+			b.WriteRune('\n')
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteXML writes the puzzle in webpbn XML format, which is read by ReadXML
+func (p *Puzzle) WriteXML(w io.Writer) error {
+	x := xmlPuzzle{
+		Type:         "grid",
+		DefaultColor: "black",
+		Title:        p.Title,
+		Author:       p.Author,
+		Copyright:    p.Copyright,
+		Colors: []xmlColor{
+			{Name: "white", Char: ".", Value: "fff"},
+			{Name: "black", Char: "X", Value: "000"},
+		},
+	}
+
+	for _, section := range []struct {
+		name  string
+		clues FillPattern
+	}{
+		{"columns", p.Columns},
+		{"rows", p.Rows},
+	} {
+		clues := xmlClues{Type: section.name}
+		for _, clue := range section.clues {
+			var line xmlLine
+			for _, size := range clue {
+				if size > 0 {
+					line.Counts = append(line.Counts, xmlCount{Value: strconv.Itoa(size)})
+				}
+			}
+			clues.Lines = append(clues.Lines, line)
+		}
+		x.Clues = append(x.Clues, clues)
+	}
+
+	data, err := xml.MarshalIndent(xmlPuzzleSet{Puzzles: []xmlPuzzle{x}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s<!DOCTYPE pbn SYSTEM \"https://webpbn.com/pbn-0.3.dtd\">\n%s\n", xml.Header, data)
+	return err
+}
+
+// complete checks that both rows and columns were read
+func (p *Puzzle) complete() (*Puzzle, error) {
+	if len(p.Rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidFormat)
+	}
+
+	if len(p.Columns) == 0 {
+		return nil, fmt.Errorf("%w: no columns", ErrInvalidFormat)
+	}
+
+	return p, nil
+}
+
+// parseClue parses space separated blocks, empty string is an empty clue
+func parseClue(s string) ([]int, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return []int{0}, nil
+	}
+
+	clue := make([]int, 0, len(fields))
+	for _, field := range fields {
+		size, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block '%s'", field)
+		}
+		clue = append(clue, size)
+	}
+
+	return clue, nil
+}
+
+// joinClue joins blocks of the clue with [sep], empty clue is "0"
+func joinClue(clue []int, sep string) string {
+	blocks := make([]string, 0, len(clue))
+	for _, size := range clue {
+		blocks = append(blocks, strconv.Itoa(size))
+	}
+
+	if len(blocks) == 0 {
+		return "0"
+	}
+
+	return strings.Join(blocks, sep)
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+
+	return s
+}
+
+func formatError(line int, format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidFormat, line, fmt.Sprintf(format, args...))
+}
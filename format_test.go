@@ -0,0 +1,234 @@
+package nonogram_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+const nonPuzzle = `catalogue "webpbn #1"
+title "Demo Puzzle"
+by "Jan Wolter"
+copyright "(c) Copyright 2004 by Jan Wolter"
+width 5
+height 4
+
+rows
+2
+1,1
+
+3
+
+columns
+1
+1,1
+2,1
+1
+0
+goal 01100101000000001110
+`
+
+const xmlPuzzle = `<?xml version="1.0"?>
+<!DOCTYPE pbn SYSTEM "https://webpbn.com/pbn-0.3.dtd">
+<puzzleset>
+<puzzle type="grid" defaultcolor="black">
+<source>webpbn.com</source>
+<id>#1</id>
+<title>Demo Puzzle</title>
+<author>Jan Wolter</author>
+<copyright>&copy; Copyright 2004 by Jan Wolter</copyright>
+<color name="white" char=".">fff</color>
+<color name="black" char="X">000</color>
+<clues type="columns">
+<line><count>1</count></line>
+<line><count>1</count><count>1</count></line>
+<line><count>2</count><count>1</count></line>
+<line><count>1</count></line>
+<line></line>
+</clues>
+<clues type="rows">
+<line><count>2</count></line>
+<line><count>1</count><count>1</count></line>
+<line></line>
+<line><count>3</count></line>
+</clues>
+</puzzle>
+</puzzleset>
+`
+
+const gPuzzle = `# Demo Puzzle
+: rows
+2
+1 1
+0
+3
+: columns
+1
+1 1
+2 1
+1
+0
+`
+
+func TestReadFormats(t *testing.T) {
+	rows := nonogram.FillPattern{{2}, {1, 1}, {0}, {3}}
+	columns := nonogram.FillPattern{{1}, {1, 1}, {2, 1}, {1}, {0}}
+
+	tests := []struct {
+		name     string
+		read     func(r io.Reader) (*nonogram.Puzzle, error)
+		input    string
+		expected nonogram.Puzzle
+	}{
+		{
+			name:  "non",
+			read:  nonogram.ReadNon,
+			input: nonPuzzle,
+			expected: nonogram.Puzzle{
+				Title:     "Demo Puzzle",
+				Author:    "Jan Wolter",
+				Copyright: "(c) Copyright 2004 by Jan Wolter",
+				Rows:      rows,
+				Columns:   columns,
+			},
+		},
+		{
+			name:  "xml",
+			read:  nonogram.ReadXML,
+			input: xmlPuzzle,
+			expected: nonogram.Puzzle{
+				Title:     "Demo Puzzle",
+				Author:    "Jan Wolter",
+				Copyright: "© Copyright 2004 by Jan Wolter",
+				Rows:      rows,
+				Columns:   columns,
+			},
+		},
+		{
+			name:     "g",
+			read:     nonogram.ReadG,
+			input:    gPuzzle,
+			expected: nonogram.Puzzle{Rows: rows, Columns: columns},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.read(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, *p)
+
+			var s nonogram.Solver
+			require.NoError(t, s.Solve(p.Rows, p.Columns))
+			require.Equal(t, ".##..\n#.#..\n.....\n.###.\n", strings.ReplaceAll(s.String(), "x", "."))
+		})
+	}
+}
+
+func TestReadFormatsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		read  func(r io.Reader) (*nonogram.Puzzle, error)
+		input string
+	}{
+		{
+			name:  "non without size",
+			read:  nonogram.ReadNon,
+			input: "rows\n1\n",
+		},
+		{
+			name:  "non with bad block",
+			read:  nonogram.ReadNon,
+			input: "width 1\nheight 1\nrows\nx\ncolumns\n1\n",
+		},
+		{
+			name:  "non with few clues",
+			read:  nonogram.ReadNon,
+			input: "width 2\nheight 1\nrows\n1\ncolumns\n1\n",
+		},
+		{
+			name:  "non without columns",
+			read:  nonogram.ReadNon,
+			input: "width 1\nheight 1\nrows\n1\n",
+		},
+		{
+			name:  "g outside of section",
+			read:  nonogram.ReadG,
+			input: "1 2\n",
+		},
+		{
+			name:  "g with colors",
+			read:  nonogram.ReadG,
+			input: ": rows\n1a\n: columns\n1a\n",
+		},
+		{
+			name:  "broken xml",
+			read:  nonogram.ReadXML,
+			input: "<puzzleset><puzzle>",
+		},
+		{
+			name:  "colored xml",
+			read:  nonogram.ReadXML,
+			input: `<puzzle><clues type="rows"><line><count color="red">1</count></line></clues></puzzle>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.read(strings.NewReader(tt.input))
+			require.ErrorIs(t, err, nonogram.ErrInvalidFormat)
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"demo.non": nonPuzzle, "demo.XML": xmlPuzzle, "demo.g": gPuzzle} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+
+		p, err := nonogram.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.Equal(t, nonogram.FillPattern{{2}, {1, 1}, {0}, {3}}, p.Rows)
+	}
+
+	_, err := nonogram.ReadFile(filepath.Join(dir, "demo.txt"))
+	require.ErrorIs(t, err, nonogram.ErrUnknownFormat)
+}
+
+func TestWriteFormats(t *testing.T) {
+	p := nonogram.Puzzle{
+		Title:     `Demo "Puzzle" & co`,
+		Author:    "Jan Wolter",
+		Copyright: "© 2004",
+		Rows:      nonogram.FillPattern{{2}, {1, 1}, {0}, {3}},
+		Columns:   nonogram.FillPattern{{1}, {1, 1}, {2, 1}, {1}, {0}},
+	}
+
+	var non bytes.Buffer
+	require.NoError(t, p.WriteNon(&non))
+	require.Contains(t, non.String(), "width 5\nheight 4\n\nrows\n2\n1,1\n0\n3\n")
+	read, err := nonogram.ReadNon(&non)
+	require.NoError(t, err)
+	require.Equal(t, p, *read)
+
+	var xml bytes.Buffer
+	require.NoError(t, p.WriteXML(&xml))
+	read, err = nonogram.ReadXML(&xml)
+	require.NoError(t, err)
+	require.Equal(t, p, *read)
+
+	dir := t.TempDir()
+	require.NoError(t, p.WriteFile(filepath.Join(dir, "demo.xml")))
+	read, err = nonogram.ReadFile(filepath.Join(dir, "demo.xml"))
+	require.NoError(t, err)
+	require.Equal(t, p, *read)
+
+	require.ErrorIs(t, p.WriteFile(filepath.Join(dir, "demo.g")), nonogram.ErrUnknownFormat)
+}
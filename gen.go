@@ -1,18 +1,337 @@
 package nonogram
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/rand"
+	"strconv"
+	"strings"
 )
 
+var ErrGenerationFailed = errors.New("failed to generate a uniquely solvable puzzle")
+var ErrInvalidPuzzleID = errors.New("invalid puzzle id")
+
+// Gen returns nonogram of size n x m with randomly filled cells
 func Gen(n, m int) *Nonogram {
+	return gen(n, m, rand.Uint64)
+}
+
+// GenRand is like Gen but takes random numbers from [r],
+// so the same source always gives the same nonogram
+func GenRand(r *rand.Rand, n, m int) *Nonogram {
+	return gen(n, m, r.Uint64)
+}
+
+func gen(n, m int, random func() uint64) *Nonogram {
 	size := EncodedSize(n, m)
 
 	data := make([]uint64, size)
 	for i := range size {
-		data[i] = rand.Uint64()
+		data[i] = random()
+	}
+
+	// bits past n*m cells are not part of the grid
+	if tail := n * m % numBits; tail != 0 {
+		data[size-1] &= 1<<tail - 1
 	}
 
 	res, _ := FromGrid(n, m, data)
 
 	return res
 }
+
+// Symmetry of generated pictures
+type Symmetry int
+
+const (
+	NoSymmetry Symmetry = iota
+	// left half of the picture mirrors the right half
+	HorizontalSymmetry
+	// top half of the picture mirrors the bottom half
+	VerticalSymmetry
+	// picture stays the same when rotated by 180 degrees
+	RotationalSymmetry
+)
+
+const (
+	defaultDensity     = 0.5
+	defaultMaxAttempts = 5000
+)
+
+// Generator produces puzzles that have a unique solution,
+// which can be found by the line solver without any guesses.
+// Zero value is ready to use. Generators with the same options
+// produce the same sequence of puzzles.
+type Generator struct {
+	// probability of a cell to be filled in the initial grid,
+	// 0.5 is used when Density is not in (0, 1)
+	Density float64
+	// seed of the random source, it's ignored when Rand is set
+	Seed int64
+	// random source, when nil a source seeded with Seed is used
+	Rand *rand.Rand
+	// symmetry of generated pictures
+	Symmetry Symmetry
+	// minimal number of passes over rows and columns
+	// the line solver needs to solve generated puzzle
+	MinDifficulty int
+	// maximal number of grid mutations before giving up,
+	// 5000 is used when MaxAttempts <= 0
+	MaxAttempts int
+
+	rnd *rand.Rand
+}
+
+// Generate returns nonogram of size n x m with uniquely solvable FillPatterns.
+// It starts from a random grid and, while the line solver gets stuck
+// on the grid's FillPatterns, flips one of the cells the solver
+// couldn't deduce. Returns ErrGenerationFailed if no puzzle was found
+// in MaxAttempts mutations.
+func (g *Generator) Generate(n, m int) (*Nonogram, error) {
+	gram, _, err := g.GenerateWithID(n, m)
+	return gram, err
+}
+
+// GenerateWithID is like Generate but also returns ID of the puzzle.
+// FromPuzzleID regenerates exactly the same puzzle from the ID.
+func (g *Generator) GenerateWithID(n, m int) (*Nonogram, PuzzleID, error) {
+	if n <= 0 || m <= 0 {
+		return nil, PuzzleID{}, ErrInvalidSize
+	}
+
+	r := g.Rand
+	if r == nil {
+		if g.rnd == nil {
+			g.rnd = rand.New(rand.NewSource(g.Seed))
+		}
+		r = g.rnd
+	}
+
+	id := PuzzleID{
+		N:             n,
+		M:             m,
+		Seed:          r.Int63(),
+		Density:       g.Density,
+		Symmetry:      g.Symmetry,
+		MinDifficulty: g.MinDifficulty,
+		MaxAttempts:   g.MaxAttempts,
+	}.normalize()
+
+	gram, err := FromPuzzleID(id)
+	if err != nil {
+		return nil, PuzzleID{}, err
+	}
+
+	return gram, id, nil
+}
+
+// PuzzleID holds everything needed to regenerate a puzzle.
+// Its string form can be printed and parsed back with ParsePuzzleID.
+type PuzzleID struct {
+	N, M          int
+	Seed          int64
+	Density       float64
+	Symmetry      Symmetry
+	MinDifficulty int
+	MaxAttempts   int
+}
+
+// normalize replaces options out of range with the defaults
+func (id PuzzleID) normalize() PuzzleID {
+	if id.Density <= 0 || id.Density >= 1 {
+		id.Density = defaultDensity
+	}
+
+	if id.MaxAttempts <= 0 {
+		id.MaxAttempts = defaultMaxAttempts
+	}
+
+	// Passes are never negative, so negative difficulty means no limit
+	if id.MinDifficulty < 0 {
+		id.MinDifficulty = 0
+	}
+
+	return id
+}
+
+var symmetryCodes = map[Symmetry]string{
+	HorizontalSymmetry: "h",
+	VerticalSymmetry:   "v",
+	RotationalSymmetry: "r",
+}
+
+// String encodes ID as size and base36 seed followed by options
+// that differ from the defaults, for example 15x20-2fqbz1-d0.6-sr-k3.
+// Seed is written as unsigned, density without exponent and difficulty
+// only when it's positive, so none of them contains the '-' separator.
+func (id PuzzleID) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%dx%d-%s", id.N, id.M, strconv.FormatUint(uint64(id.Seed), 36))
+	if id.Density != defaultDensity {
+		fmt.Fprintf(&b, "-d%s", strconv.FormatFloat(id.Density, 'f', -1, 64))
+	}
+	if code, ok := symmetryCodes[id.Symmetry]; ok {
+		fmt.Fprintf(&b, "-s%s", code)
+	}
+	if id.MinDifficulty > 0 {
+		fmt.Fprintf(&b, "-k%d", id.MinDifficulty)
+	}
+	if id.MaxAttempts != defaultMaxAttempts {
+		fmt.Fprintf(&b, "-a%d", id.MaxAttempts)
+	}
+
+	return b.String()
+}
+
+// ParsePuzzleID parses ID printed by PuzzleID.String
+func ParsePuzzleID(s string) (PuzzleID, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 2 {
+		return PuzzleID{}, fmt.Errorf("%w '%s': expected size and seed", ErrInvalidPuzzleID, s)
+	}
+
+	id := PuzzleID{Density: defaultDensity, MaxAttempts: defaultMaxAttempts}
+
+	if _, err := fmt.Sscanf(parts[0], "%dx%d", &id.N, &id.M); err != nil {
+		return PuzzleID{}, fmt.Errorf("%w '%s': failed to parse size '%s'", ErrInvalidPuzzleID, s, parts[0])
+	}
+
+	seed, err := strconv.ParseUint(parts[1], 36, 64)
+	if err != nil {
+		return PuzzleID{}, fmt.Errorf("%w '%s': failed to parse seed '%s'", ErrInvalidPuzzleID, s, parts[1])
+	}
+	id.Seed = int64(seed)
+
+	for _, option := range parts[2:] {
+		if option == "" {
+			return PuzzleID{}, fmt.Errorf("%w '%s': empty option", ErrInvalidPuzzleID, s)
+		}
+
+		value := option[1:]
+		switch option[0] {
+		case 'd':
+			id.Density, err = strconv.ParseFloat(value, 64)
+		case 'k':
+			id.MinDifficulty, err = strconv.Atoi(value)
+		case 'a':
+			id.MaxAttempts, err = strconv.Atoi(value)
+		case 's':
+			err = fmt.Errorf("unknown symmetry")
+			for symmetry, code := range symmetryCodes {
+				if code == value {
+					id.Symmetry = symmetry
+					err = nil
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+
+		if err != nil {
+			return PuzzleID{}, fmt.Errorf("%w '%s': option '%s': %v", ErrInvalidPuzzleID, s, option, err)
+		}
+	}
+
+	return id, nil
+}
+
+// FromPuzzleID generates the puzzle described by [id]
+func FromPuzzleID(id PuzzleID) (*Nonogram, error) {
+	n, m := id.N, id.M
+	if n <= 0 || m <= 0 {
+		return nil, ErrInvalidSize
+	}
+
+	id = id.normalize()
+
+	r := rand.New(rand.NewSource(id.Seed))
+
+	gram := New(n, m)
+	for i := range n {
+		for j := range m {
+			if id.Symmetry.isMirror(n, m, i, j) {
+				continue
+			}
+			if r.Float64() < id.Density {
+				id.Symmetry.set(gram, i, j, true)
+			}
+		}
+	}
+
+	unknown := make([][2]int, 0, n*m)
+	for range id.MaxAttempts {
+		var s Solver
+		if err := s.init(gram.FillPatterns()); err != nil {
+			return nil, err
+		}
+
+		if err := s.propagate(context.Background()); err != nil {
+			return nil, err
+		}
+
+		if s.isSolved() && s.stats.Passes >= id.MinDifficulty {
+			return gram, nil
+		}
+
+		// filling undeduced cells makes lines denser and easier to solve,
+		// so blank cells are flipped first
+		unknown = unknown[:0]
+		for _, filled := range []bool{false, true} {
+			for i := range n {
+				for j := range m {
+					if s.get(i, j) == Unknown && gram.Get(i, j) == filled {
+						unknown = append(unknown, [2]int{i, j})
+					}
+				}
+			}
+			if len(unknown) > 0 {
+				break
+			}
+		}
+
+		// puzzle is solvable but too easy, so any cell may be changed
+		if len(unknown) == 0 {
+			unknown = append(unknown, [2]int{r.Intn(n), r.Intn(m)})
+		}
+
+		cell := unknown[r.Intn(len(unknown))]
+		id.Symmetry.set(gram, cell[0], cell[1], !gram.Get(cell[0], cell[1]))
+	}
+
+	return nil, ErrGenerationFailed
+}
+
+// set changes cell (i, j) and its mirrored cell
+func (s Symmetry) set(gram *Nonogram, i, j int, filled bool) {
+	mi, mj := s.mirror(gram.n, gram.m, i, j)
+	for _, cell := range [][2]int{{i, j}, {mi, mj}} {
+		if filled {
+			gram.Fill(cell[0], cell[1])
+		} else {
+			gram.Clear(cell[0], cell[1])
+		}
+	}
+}
+
+// mirror returns cell that must have the same state as (i, j)
+func (s Symmetry) mirror(n, m, i, j int) (int, int) {
+	switch s {
+	case HorizontalSymmetry:
+		return i, m - 1 - j
+	case VerticalSymmetry:
+		return n - 1 - i, j
+	case RotationalSymmetry:
+		return n - 1 - i, m - 1 - j
+	default:
+		return i, j
+	}
+}
+
+// isMirror reports if cell (i, j) is defined by another cell
+// which comes earlier in row-major order
+func (s Symmetry) isMirror(n, m, i, j int) bool {
+	mi, mj := s.mirror(n, m, i, j)
+	return mi*m+mj < i*m+j
+}
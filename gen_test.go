@@ -0,0 +1,192 @@
+package nonogram_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		n, m     int
+		symmetry nonogram.Symmetry
+	}{
+		{name: "square", n: 10, m: 10},
+		{name: "wide", n: 5, m: 15},
+		{name: "tall", n: 15, m: 5},
+		{name: "horizontal symmetry", n: 10, m: 10, symmetry: nonogram.HorizontalSymmetry},
+		{name: "vertical symmetry", n: 9, m: 12, symmetry: nonogram.VerticalSymmetry},
+		{name: "rotational symmetry", n: 11, m: 7, symmetry: nonogram.RotationalSymmetry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := nonogram.Generator{Seed: 42, Symmetry: tt.symmetry}
+			for range 10 {
+				gram, err := g.Generate(tt.n, tt.m)
+				require.NoError(t, err)
+
+				var s nonogram.Solver
+				unique, err := s.IsUnique(gram.FillPatterns())
+				require.NoError(t, err)
+				require.True(t, unique)
+
+				for i := range tt.n {
+					for j := range tt.m {
+						mi, mj := i, j
+						switch tt.symmetry {
+						case nonogram.HorizontalSymmetry:
+							mj = tt.m - 1 - j
+						case nonogram.VerticalSymmetry:
+							mi = tt.n - 1 - i
+						case nonogram.RotationalSymmetry:
+							mi, mj = tt.n-1-i, tt.m-1-j
+						}
+						require.Equal(t, gram.Get(i, j), gram.Get(mi, mj))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSameSeed(t *testing.T) {
+	first := nonogram.Generator{Seed: 7, Density: 0.6}
+	second := nonogram.Generator{Seed: 7, Density: 0.6}
+
+	for range 5 {
+		a, err := first.Generate(12, 8)
+		require.NoError(t, err)
+
+		b, err := second.Generate(12, 8)
+		require.NoError(t, err)
+
+		require.Equal(t, a.String(), b.String())
+	}
+}
+
+func TestGenerateFailed(t *testing.T) {
+	g := nonogram.Generator{MinDifficulty: 1000, MaxAttempts: 10}
+
+	_, err := g.Generate(5, 5)
+	require.ErrorIs(t, err, nonogram.ErrGenerationFailed)
+
+	_, err = g.Generate(0, 5)
+	require.ErrorIs(t, err, nonogram.ErrInvalidSize)
+}
+
+func TestGenerateGolden(t *testing.T) {
+	g := nonogram.Generator{Seed: 1}
+	gram, id, err := g.GenerateWithID(8, 10)
+	require.NoError(t, err)
+	require.Equal(t, "8x10-16ddfwutxgjte", id.String())
+	require.Equal(t, `..#.#.##..
+#.#.##.###
+###.#.#...
+..#.....##
+.###.#..##
+########..
+.#..##..#.
+######..##
+`, gram.String())
+
+	g = nonogram.Generator{Seed: 3, Density: 0.6, Symmetry: nonogram.RotationalSymmetry, MinDifficulty: 2}
+	gram, id, err = g.GenerateWithID(6, 5)
+	require.NoError(t, err)
+	require.Equal(t, "6x5-1egaociutae85-d0.6-sr-k2", id.String())
+	require.Equal(t, `.###.
+.#...
+###.#
+#.###
+...#.
+.###.
+`, gram.String())
+
+	gram = nonogram.GenRand(rand.New(rand.NewSource(5)), 4, 6)
+	require.Equal(t, `......
+###..#
+....##
+..#...
+`, gram.String())
+}
+
+func TestGenerateRand(t *testing.T) {
+	first := nonogram.Generator{Rand: rand.New(rand.NewSource(11))}
+	second := nonogram.Generator{Rand: rand.New(rand.NewSource(11)), Seed: 12}
+
+	for range 5 {
+		a, err := first.Generate(7, 9)
+		require.NoError(t, err)
+
+		b, err := second.Generate(7, 9)
+		require.NoError(t, err)
+
+		require.Equal(t, a.String(), b.String())
+	}
+}
+
+func TestPuzzleID(t *testing.T) {
+	g := nonogram.Generator{Seed: 5, Density: 0.65, Symmetry: nonogram.HorizontalSymmetry, MaxAttempts: 7000}
+	for range 5 {
+		gram, id, err := g.GenerateWithID(9, 6)
+		require.NoError(t, err)
+
+		parsed, err := nonogram.ParsePuzzleID(id.String())
+		require.NoError(t, err)
+		require.Equal(t, id, parsed)
+
+		regenerated, err := nonogram.FromPuzzleID(parsed)
+		require.NoError(t, err)
+		require.Equal(t, gram.String(), regenerated.String())
+	}
+
+	// small densities and negative seeds have no '-' in their string form
+	for expected, id := range map[string]nonogram.PuzzleID{
+		"5x5-3-d0.00001":    {N: 5, M: 5, Seed: 3, Density: 0.00001, MaxAttempts: 5000},
+		"5x5-3w5e11264sgsd": {N: 5, M: 5, Seed: -3, Density: 0.5, MaxAttempts: 5000},
+	} {
+		require.Equal(t, expected, id.String())
+
+		parsed, err := nonogram.ParsePuzzleID(id.String())
+		require.NoError(t, err)
+		require.Equal(t, id, parsed)
+	}
+
+	// negative difficulty is the same as no difficulty
+	g = nonogram.Generator{Seed: 5, MinDifficulty: -2}
+	gram, id, err := g.GenerateWithID(5, 6)
+	require.NoError(t, err)
+	require.Zero(t, id.MinDifficulty)
+
+	parsed, err := nonogram.ParsePuzzleID(id.String())
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	regenerated, err := nonogram.FromPuzzleID(parsed)
+	require.NoError(t, err)
+	require.Equal(t, gram.String(), regenerated.String())
+}
+
+func TestParsePuzzleIDInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"15x15",
+		"15-abc",
+		"15x15-!!",
+		"15x15-abc-",
+		"15x15-abc-dx",
+		"15x15-abc-sq",
+		"15x15-abc-z1",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := nonogram.ParsePuzzleID(tt)
+			require.ErrorIs(t, err, nonogram.ErrInvalidPuzzleID)
+		})
+	}
+}
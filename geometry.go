@@ -0,0 +1,287 @@
+package nonogram
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrClueCount = errors.New("number of clues differs from number of lines")
+var ErrNotRectangular = errors.New("puzzle is not rectangular")
+var ErrCellRange = errors.New("line has a cell out of range")
+var ErrSharedCell = errors.New("line shares a cell with another line of its axis")
+var ErrAxisOrder = errors.New("lines of one axis are not listed together")
+
+// Geometry describes the shape of a puzzle as a collection of lines
+// over numbered cells. The solver knows nothing else about the shape,
+// so any grid whose lines have clues can be solved.
+type Geometry interface {
+	// Cells returns number of cells, they are numbered from 0
+	Cells() int
+	// Lines returns every line of the puzzle, cells of a line
+	// go in the order its clue describes them. Lines of one Axis
+	// must be listed together and have no common cells, so the solver
+	// can solve them independently.
+	Lines() []Line
+	// Position returns the row and the column of the cell
+	// used to report it in steps of solving
+	Position(cell int) (row, column int)
+}
+
+// Line of a puzzle, Index is the number of the line along its Axis
+type Line struct {
+	Axis  Axis
+	Index int
+	Cells []int
+}
+
+// validateLines checks that cells of [lines] are in range and lines
+// of every axis go one after another and have no common cells
+func validateLines(cells int, lines []Line, clues [][]int) *ClueError {
+	// owner[cell] is the number of the last axis group having the cell,
+	// groups are numbered from 1
+	owner := make([]int, cells)
+	seen := make(map[Axis]bool)
+	group := 0
+	for k, line := range lines {
+		problem := &ClueError{Axis: line.Axis, Index: line.Index, Clue: clues[k]}
+		if k == 0 || line.Axis != lines[k-1].Axis {
+			if seen[line.Axis] {
+				problem.Reason = ErrAxisOrder
+				return problem
+			}
+			seen[line.Axis] = true
+			group++
+		}
+
+		for _, cell := range line.Cells {
+			switch {
+			case cell < 0 || cell >= cells:
+				problem.Reason = ErrCellRange
+			case owner[cell] == group:
+				problem.Reason = ErrSharedCell
+			default:
+				owner[cell] = group
+				continue
+			}
+			return problem
+		}
+	}
+
+	return nil
+}
+
+// formatter is implemented by geometries that can be printed
+type formatter interface {
+	format(cells []State, fill, empty, unknown rune, cage int) string
+}
+
+// Clues returns clues of every line of [g] for the picture
+// where [filled] reports if the cell is filled
+func Clues(g Geometry, filled func(cell int) bool) [][]int {
+	lines := g.Lines()
+	clues := make([][]int, len(lines))
+	for k, line := range lines {
+		blockSize := 0
+		for _, cell := range line.Cells {
+			if filled(cell) {
+				blockSize++
+			} else {
+				if blockSize > 0 {
+					clues[k] = append(clues[k], blockSize)
+				}
+				blockSize = 0
+			}
+		}
+
+		if blockSize != 0 || len(clues[k]) == 0 {
+			clues[k] = append(clues[k], blockSize)
+		}
+	}
+
+	return clues
+}
+
+// Rect is a rectangular grid of N rows and M columns.
+// Cell (i, j) has number i*M+j, its rows go before its columns.
+type Rect struct {
+	N, M int
+}
+
+func (r Rect) Cells() int {
+	return r.N * r.M
+}
+
+func (r Rect) Lines() []Line {
+	if r.N <= 0 || r.M <= 0 {
+		return nil
+	}
+
+	lines := make([]Line, 0, r.N+r.M)
+	for i := range r.N {
+		cells := make([]int, r.M)
+		for j := range r.M {
+			cells[j] = i*r.M + j
+		}
+		lines = append(lines, Line{Axis: Row, Index: i, Cells: cells})
+	}
+
+	for j := range r.M {
+		cells := make([]int, r.N)
+		for i := range r.N {
+			cells[i] = i*r.M + j
+		}
+		lines = append(lines, Line{Axis: Column, Index: j, Cells: cells})
+	}
+
+	return lines
+}
+
+func (r Rect) Position(cell int) (int, int) {
+	return cell / r.M, cell % r.M
+}
+
+// cage=0 means no cage
+func (r Rect) format(cells []State, fill, empty, unknown rune, cage int) string {
+	var b strings.Builder
+
+	for i := range r.N {
+		if cage != 0 && i != 0 && i%cage == 0 {
+			for k := range r.M {
+				if k != 0 && k%cage == 0 {
+					b.WriteRune('┼')
+				}
+				b.WriteRune('─')
+			}
+			b.WriteRune('\n')
+		}
+		for j := range r.M {
+			if cage != 0 && j != 0 && j%cage == 0 {
+				b.WriteRune('│')
+			}
+			b.WriteRune(stateRune(cells[i*r.M+j], fill, empty, unknown))
+		}
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+// Hex is a hexagon of hexagonal cells with Side cells on every side.
+// It has 2*Side-1 rows and lines along two more axes:
+// Diagonal goes from the top left to the bottom right
+// and AntiDiagonal goes from the top right to the bottom left.
+// Cells are numbered row by row, every line goes from top to bottom.
+//
+// Position of a cell is its row and its diagonal.
+type Hex struct {
+	Side int
+}
+
+func (h Hex) Cells() int {
+	if h.Side <= 0 {
+		return 0
+	}
+
+	return 3*h.Side*(h.Side-1) + 1
+}
+
+func (h Hex) Lines() []Line {
+	size := 2*h.Side - 1
+	if size <= 0 {
+		return nil
+	}
+
+	lines := make([]Line, 0, 3*size)
+	for r := range size {
+		var cells []int
+		for q := h.first(r); q <= h.last(r); q++ {
+			cells = append(cells, h.cell(r, q))
+		}
+		lines = append(lines, Line{Axis: Row, Index: r, Cells: cells})
+	}
+
+	for q := range size {
+		var cells []int
+		for r := range size {
+			if h.first(r) <= q && q <= h.last(r) {
+				cells = append(cells, h.cell(r, q))
+			}
+		}
+		lines = append(lines, Line{Axis: Diagonal, Index: q, Cells: cells})
+	}
+
+	// cells of an anti-diagonal have the same r+q
+	for t := range size {
+		var cells []int
+		for r := range size {
+			if q := t + h.Side - 1 - r; h.first(r) <= q && q <= h.last(r) {
+				cells = append(cells, h.cell(r, q))
+			}
+		}
+		lines = append(lines, Line{Axis: AntiDiagonal, Index: t, Cells: cells})
+	}
+
+	return lines
+}
+
+func (h Hex) Position(cell int) (int, int) {
+	r := 0
+	for cell > h.last(r)-h.first(r) {
+		cell -= h.last(r) - h.first(r) + 1
+		r++
+	}
+
+	return r, h.first(r) + cell
+}
+
+// every row is shifted by half a cell, cells are separated by spaces
+func (h Hex) format(cells []State, fill, empty, unknown rune, _ int) string {
+	var b strings.Builder
+
+	for r := range 2*h.Side - 1 {
+		b.WriteString(strings.Repeat(" ", h.shift(r)))
+		for q := h.first(r); q <= h.last(r); q++ {
+			if q != h.first(r) {
+				b.WriteRune(' ')
+			}
+			b.WriteRune(stateRune(cells[h.cell(r, q)], fill, empty, unknown))
+		}
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
+
+// shift returns distance of the row from the middle one
+func (h Hex) shift(r int) int {
+	return max(h.Side-1-r, r-h.Side+1)
+}
+
+// first and last return diagonals of the first and the last cells of the row
+func (h Hex) first(r int) int {
+	return max(0, h.Side-1-r)
+}
+
+func (h Hex) last(r int) int {
+	return min(2*h.Side-2, 3*h.Side-3-r)
+}
+
+func (h Hex) cell(r, q int) int {
+	cell := 0
+	for k := range r {
+		cell += h.last(k) - h.first(k) + 1
+	}
+
+	return cell + q - h.first(r)
+}
+
+func stateRune(state State, fill, empty, unknown rune) rune {
+	switch state {
+	case Filled:
+		return fill
+	case Blank:
+		return empty
+	default:
+		return unknown
+	}
+}
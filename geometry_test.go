@@ -0,0 +1,185 @@
+package nonogram_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexLines(t *testing.T) {
+	//  0 1
+	// 2 3 4
+	//  5 6
+	hex := nonogram.Hex{Side: 2}
+	require.Equal(t, 7, hex.Cells())
+	require.Equal(t, []nonogram.Line{
+		{Axis: nonogram.Row, Index: 0, Cells: []int{0, 1}},
+		{Axis: nonogram.Row, Index: 1, Cells: []int{2, 3, 4}},
+		{Axis: nonogram.Row, Index: 2, Cells: []int{5, 6}},
+		{Axis: nonogram.Diagonal, Index: 0, Cells: []int{2, 5}},
+		{Axis: nonogram.Diagonal, Index: 1, Cells: []int{0, 3, 6}},
+		{Axis: nonogram.Diagonal, Index: 2, Cells: []int{1, 4}},
+		{Axis: nonogram.AntiDiagonal, Index: 0, Cells: []int{0, 2}},
+		{Axis: nonogram.AntiDiagonal, Index: 1, Cells: []int{1, 3, 5}},
+		{Axis: nonogram.AntiDiagonal, Index: 2, Cells: []int{4, 6}},
+	}, hex.Lines())
+
+	for cell, expected := range [][2]int{{0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {2, 0}, {2, 1}} {
+		row, column := hex.Position(cell)
+		require.Equal(t, expected, [2]int{row, column})
+	}
+}
+
+func TestRectClues(t *testing.T) {
+	gram := nonogram.Gen(7, 9)
+	rect := nonogram.Rect{N: 7, M: 9}
+	clues := nonogram.Clues(rect, func(cell int) bool {
+		return gram.Get(rect.Position(cell))
+	})
+
+	rows, columns := gram.FillPatterns()
+	require.Equal(t, append(rows, columns...), nonogram.FillPattern(clues))
+}
+
+func TestSolveHex(t *testing.T) {
+	hex := nonogram.Hex{Side: 3}
+	picture := map[int]bool{0: true, 1: true, 3: true, 8: true, 9: true, 10: true, 15: true, 18: true}
+	clues := nonogram.Clues(hex, func(cell int) bool { return picture[cell] })
+
+	var s nonogram.Solver
+	require.NoError(t, s.SolveGeometry(context.Background(), hex, clues))
+	require.Equal(t, ""+
+		"  # # x\n"+
+		" # x x x\n"+
+		"x # # # x\n"+
+		" x x x #\n"+
+		"  x x #\n", s.String())
+	require.Nil(t, s.ToNonogram())
+	require.ErrorIs(t, s.SavePNG("hex.png", 1), nonogram.ErrNotRectangular)
+}
+
+func TestSolveHexRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for side := 1; side <= 6; side++ {
+		hex := nonogram.Hex{Side: side}
+		for range 10 {
+			picture := make([]bool, hex.Cells())
+			for cell := range picture {
+				picture[cell] = r.Intn(2) == 0
+			}
+			clues := nonogram.Clues(hex, func(cell int) bool { return picture[cell] })
+
+			var s nonogram.Solver
+			require.NoError(t, s.SolveGeometry(context.Background(), hex, clues))
+
+			cells := s.Cells()
+			require.Equal(t, clues, nonogram.Clues(hex, func(cell int) bool { return cells[cell] == nonogram.Filled }))
+		}
+	}
+}
+
+func TestValidateGeometry(t *testing.T) {
+	hex := nonogram.Hex{Side: 2}
+
+	err := nonogram.ValidateGeometry(hex, [][]int{{1}})
+	require.ErrorIs(t, err, nonogram.ErrClueCount)
+
+	err = nonogram.ValidateGeometry(nonogram.Hex{}, nil)
+	require.ErrorIs(t, err, nonogram.ErrInvalidSize)
+
+	err = nonogram.ValidateGeometry(hex, [][]int{{2}, {0}, {0}, {0}, {1}, {1}, {1}, {0}, {0}})
+	require.ErrorIs(t, err, nonogram.ErrFillMismatch)
+
+	err = nonogram.ValidateGeometry(hex, [][]int{{2}, {0}, {0}, {0}, {1}, {1}, {1}, {1}, {3}})
+	var validationErr *nonogram.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, "invalid clues: anti-diagonal 2 [3]: blocks with gaps between them are longer than the line", err.Error())
+
+	var s nonogram.Solver
+	err = s.SolveGeometry(context.Background(), hex, [][]int{{2}, {0}, {0}, {1}, {0}, {1}, {1}, {1}, {0}})
+	var contradiction *nonogram.ContradictionError
+	require.ErrorAs(t, err, &contradiction)
+	require.ErrorIs(t, err, nonogram.ErrContradiction)
+}
+
+// lineGeometry is a geometry made of arbitrary lines
+type lineGeometry struct {
+	cells int
+	lines []nonogram.Line
+}
+
+func (g lineGeometry) Cells() int {
+	return g.cells
+}
+
+func (g lineGeometry) Lines() []nonogram.Line {
+	return g.lines
+}
+
+func (g lineGeometry) Position(cell int) (row, column int) {
+	return 0, cell
+}
+
+func TestValidateGeometryLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []nonogram.Line
+		expected error
+		message  string
+	}{
+		{
+			name: "shared cell",
+			lines: []nonogram.Line{
+				{Axis: nonogram.Row, Index: 0, Cells: []int{0, 1}},
+				{Axis: nonogram.Row, Index: 1, Cells: []int{1, 2}},
+			},
+			expected: nonogram.ErrSharedCell,
+			message:  "invalid clues: row 1 [0]: line shares a cell with another line of its axis",
+		},
+		{
+			name: "axis order",
+			lines: []nonogram.Line{
+				{Axis: nonogram.Row, Index: 0, Cells: []int{0, 1, 2}},
+				{Axis: nonogram.Column, Index: 0, Cells: []int{0, 1, 2}},
+				{Axis: nonogram.Row, Index: 1, Cells: []int{0, 1, 2}},
+			},
+			expected: nonogram.ErrAxisOrder,
+			message:  "invalid clues: row 1 [0]: lines of one axis are not listed together",
+		},
+		{
+			name: "cell out of range",
+			lines: []nonogram.Line{
+				{Axis: nonogram.Row, Index: 0, Cells: []int{0, 1}},
+				{Axis: nonogram.Row, Index: 1, Cells: []int{2, 3}},
+			},
+			expected: nonogram.ErrCellRange,
+			message:  "invalid clues: row 1 [0]: line has a cell out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := lineGeometry{cells: 3, lines: tt.lines}
+			clues := make([][]int, len(tt.lines))
+			clues[0] = []int{len(tt.lines[0].Cells)}
+			for k := 1; k < len(clues); k++ {
+				clues[k] = []int{0}
+			}
+
+			err := nonogram.ValidateGeometry(g, clues)
+			require.ErrorIs(t, err, tt.expected)
+			require.EqualError(t, err, tt.message)
+
+			// lines are checked before solving, so solving them
+			// concurrently can't give a different result
+			for _, parallel := range []bool{false, true} {
+				s := nonogram.Solver{Parallel: parallel}
+				require.ErrorIs(t, s.SolveGeometry(context.Background(), g, clues), tt.expected)
+			}
+		})
+	}
+}
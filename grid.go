@@ -0,0 +1,111 @@
+package nonogram
+
+import "math/bits"
+
+// grid holds states of the solver's cells in two bit planes packed
+// the same way as Nonogram.grid: a bit of known is set when the cell
+// is Filled or Blank, and a bit of filled is set when it's Filled.
+// A cell takes 2 bits, so copying the grid for a guess is cheap.
+type grid struct {
+	size   int
+	known  []uint64
+	filled []uint64
+}
+
+func newGrid(size int) grid {
+	words := (size + numBits - 1) / numBits
+
+	return grid{
+		size:   size,
+		known:  make([]uint64, words),
+		filled: make([]uint64, words),
+	}
+}
+
+func (g *grid) get(cell int) State {
+	word, bit := cell/numBits, uint64(1)<<(cell%numBits)
+	switch {
+	case g.known[word]&bit == 0:
+		return Unknown
+	case g.filled[word]&bit != 0:
+		return Filled
+	default:
+		return Blank
+	}
+}
+
+func (g *grid) set(cell int, state State) {
+	word, bit := cell/numBits, uint64(1)<<(cell%numBits)
+	switch state {
+	case Filled:
+		g.known[word] |= bit
+		g.filled[word] |= bit
+	case Blank:
+		g.known[word] |= bit
+		g.filled[word] &^= bit
+	default:
+		g.known[word] &^= bit
+		g.filled[word] &^= bit
+	}
+}
+
+// clone returns a snapshot of the grid
+func (g *grid) clone() grid {
+	return grid{
+		size:   g.size,
+		known:  append([]uint64(nil), g.known...),
+		filled: append([]uint64(nil), g.filled...),
+	}
+}
+
+// isSolved reports if every cell is known
+func (g *grid) isSolved() bool {
+	for word, known := range g.known {
+		if known != g.mask(word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mask returns bits of the word that hold cells of the grid,
+// the last word may be filled partially
+func (g *grid) mask(word int) uint64 {
+	if tail := g.size - word*numBits; tail < numBits {
+		return 1<<tail - 1
+	}
+
+	return 1<<numBits - 1
+}
+
+// common returns cells which are unknown in the grid, but known
+// and have the same state in all [others]
+func (g *grid) common(others ...*grid) []int {
+	var cells []int
+	for word := range g.known {
+		mask := ^g.known[word] & g.mask(word)
+		for _, other := range others {
+			mask &= other.known[word]
+			mask &^= other.filled[word] ^ others[0].filled[word]
+		}
+
+		for mask != 0 {
+			bit := bits.TrailingZeros64(mask)
+			cells = append(cells, word*numBits+bit)
+			mask &= mask - 1
+		}
+	}
+
+	return cells
+}
+
+// states returns state of every cell
+func (g *grid) states() []State {
+	states := make([]State, g.size)
+	for cell := range states {
+		states[cell] = g.get(cell)
+	}
+
+	return states
+}
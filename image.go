@@ -0,0 +1,232 @@
+package nonogram
+
+import (
+	"context"
+	"image"
+	"math"
+)
+
+// ImageConverter turns pictures into nonograms. Zero value is ready to use:
+// it fills cells darker than Otsu's threshold of the picture and doesn't
+// change the result to make it unique.
+type ImageConverter struct {
+	// luminance from 0 (black) to 1 (white) below which cells are filled,
+	// Otsu's threshold of the picture is used when it's not in (0, 1)
+	Threshold float64
+	// spread the rounding error of every cell to its neighbours
+	// with Floyd-Steinberg dithering, so gray areas become
+	// patterns of filled and blank cells instead of solid ones
+	Dither bool
+	// flip cells the line solver can't deduce until the puzzle is unique
+	MakeUnique bool
+	// maximal number of flipped cells, n*m is used when MaxFlips <= 0
+	MaxFlips int
+}
+
+// Convert downsamples [img] to n x m cells and fills dark cells.
+// It also reports if FillPatterns of the nonogram have a unique solution.
+// Transparent pixels are treated as white.
+func (c *ImageConverter) Convert(img image.Image, n, m int) (*Nonogram, bool, error) {
+	return c.ConvertContext(context.Background(), img, n, m)
+}
+
+// ConvertContext is like Convert but stops as soon as [ctx] is done,
+// then it returns ctx.Err(). Context is checked between propagation
+// passes and on every guess of the uniqueness check.
+func (c *ImageConverter) ConvertContext(ctx context.Context, img image.Image, n, m int) (*Nonogram, bool, error) {
+	if n <= 0 || m <= 0 || img.Bounds().Empty() {
+		return nil, false, ErrInvalidSize
+	}
+
+	lum := downsample(img, n, m)
+
+	threshold := c.Threshold
+	if threshold <= 0 || threshold >= 1 {
+		threshold = otsuThreshold(lum)
+	}
+
+	gram := New(n, m)
+	if c.Dither {
+		ditherGrid(gram, lum, threshold)
+	} else {
+		for i := range n {
+			for j := range m {
+				if lum[i][j] < threshold {
+					gram.Fill(i, j)
+				}
+			}
+		}
+	}
+
+	if !c.MakeUnique {
+		var s Solver
+		rows, columns := gram.FillPatterns()
+		unique, err := s.isUnique(ctx, rows, columns)
+		if err != nil {
+			return nil, false, err
+		}
+		return gram, unique, nil
+	}
+
+	unique, err := c.makeUnique(ctx, gram, lum, threshold)
+	if err != nil {
+		return nil, false, err
+	}
+	return gram, unique, nil
+}
+
+// makeUnique flips cells the line solver can't deduce, starting from the cells
+// whose luminance is the closest to the threshold, so the picture changes
+// as little as possible. Every cell is flipped at most once.
+func (c *ImageConverter) makeUnique(ctx context.Context, gram *Nonogram, lum [][]float64, threshold float64) (bool, error) {
+	n, m := gram.n, gram.m
+
+	maxFlips := c.MaxFlips
+	if maxFlips <= 0 {
+		maxFlips = n * m
+	}
+
+	flipped := make([]bool, n*m)
+	for range maxFlips {
+		var s Solver
+		if err := s.init(gram.FillPatterns()); err != nil {
+			return false, err
+		}
+
+		if err := s.propagate(ctx); err != nil {
+			return false, err
+		}
+
+		if s.isSolved() {
+			return true, nil
+		}
+
+		best := -1
+		for i := range n {
+			for j := range m {
+				if s.get(i, j) != Unknown || flipped[i*m+j] {
+					continue
+				}
+				if best == -1 || math.Abs(lum[i][j]-threshold) < math.Abs(lum[best/m][best%m]-threshold) {
+					best = i*m + j
+				}
+			}
+		}
+
+		if best == -1 {
+			break
+		}
+
+		flipped[best] = true
+		i, j := best/m, best%m
+		if gram.Get(i, j) {
+			gram.Clear(i, j)
+		} else {
+			gram.Fill(i, j)
+		}
+	}
+
+	var s Solver
+	rows, columns := gram.FillPatterns()
+	return s.isUnique(ctx, rows, columns)
+}
+
+// downsample returns average luminance of the pixels covered by every cell
+func downsample(img image.Image, n, m int) [][]float64 {
+	b := img.Bounds()
+
+	lum := make([][]float64, n)
+	for i := range n {
+		lum[i] = make([]float64, m)
+
+		y0 := b.Min.Y + i*b.Dy()/n
+		y1 := max(b.Min.Y+(i+1)*b.Dy()/n, y0+1)
+		for j := range m {
+			x0 := b.Min.X + j*b.Dx()/m
+			x1 := max(b.Min.X+(j+1)*b.Dx()/m, x0+1)
+
+			sum := 0.0
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					sum += luminance(img, x, y)
+				}
+			}
+			lum[i][j] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+
+	return lum
+}
+
+// luminance of the pixel drawn over white background, from 0 to 1
+func luminance(img image.Image, x, y int) float64 {
+	r, g, b, a := img.At(x, y).RGBA()
+	white := float64(0xffff - a)
+
+	return (0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(b)+white)) / 0xffff
+}
+
+// otsuThreshold returns the threshold which splits luminance of cells into
+// dark and light classes with the largest variance between them,
+// 0.5 is returned when all cells have the same luminance
+func otsuThreshold(lum [][]float64) float64 {
+	var histogram [256]int
+	total, sum := 0, 0.0
+	for _, row := range lum {
+		for _, l := range row {
+			level := min(255, int(l*255+0.5))
+			histogram[level]++
+			total++
+			sum += float64(level)
+		}
+	}
+
+	threshold, best := 0.5, 0.0
+	dark, darkSum := 0, 0.0
+	for t := 1; t < 256; t++ {
+		dark += histogram[t-1]
+		darkSum += float64((t - 1) * histogram[t-1])
+
+		light := total - dark
+		if dark == 0 || light == 0 {
+			continue
+		}
+
+		meanDark := darkSum / float64(dark)
+		meanLight := (sum - darkSum) / float64(light)
+		variance := float64(dark) * float64(light) * (meanDark - meanLight) * (meanDark - meanLight)
+		if variance > best {
+			threshold, best = (float64(t)-0.5)/255, variance
+		}
+	}
+
+	return threshold
+}
+
+// ditherGrid fills cells with Floyd-Steinberg dithering
+func ditherGrid(gram *Nonogram, lum [][]float64, threshold float64) {
+	n, m := gram.n, gram.m
+
+	errs := make([][]float64, n+1)
+	for i := range errs {
+		errs[i] = make([]float64, m+2)
+	}
+
+	for i := range n {
+		for j := range m {
+			value := lum[i][j] + errs[i][j+1]
+
+			quantized := 1.0
+			if value < threshold {
+				gram.Fill(i, j)
+				quantized = 0
+			}
+
+			diff := value - quantized
+			errs[i][j+2] += diff * 7 / 16
+			errs[i+1][j] += diff * 3 / 16
+			errs[i+1][j+1] += diff * 5 / 16
+			errs[i+1][j+2] += diff * 1 / 16
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package nonogram_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+// picture scales [rows] up [scale] times, '#' is a pixel of color [dark],
+// other pixels have color [light]
+func picture(rows []string, scale int, dark, light color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0])*scale, len(rows)*scale))
+	for y := range img.Bounds().Dy() {
+		for x := range img.Bounds().Dx() {
+			if rows[y/scale][x/scale] == '#' {
+				img.Set(x, y, dark)
+			} else {
+				img.Set(x, y, light)
+			}
+		}
+	}
+
+	return img
+}
+
+func TestImageConvert(t *testing.T) {
+	arrow := []string{
+		"..#..",
+		".###.",
+		"#.#.#",
+		"..#..",
+	}
+
+	tests := []struct {
+		name      string
+		converter nonogram.ImageConverter
+		img       image.Image
+		n, m      int
+		expected  string
+		unique    bool
+	}{
+		{
+			name:     "black and white",
+			img:      picture(arrow, 3, color.Black, color.White),
+			n:        4,
+			m:        5,
+			expected: "..#..\n.###.\n#.#.#\n..#..\n",
+			unique:   true,
+		},
+		{
+			name:     "otsu threshold of gray picture",
+			img:      picture(arrow, 3, color.Gray{120}, color.Gray{160}),
+			n:        4,
+			m:        5,
+			expected: "..#..\n.###.\n#.#.#\n..#..\n",
+			unique:   true,
+		},
+		{
+			name:      "fixed threshold",
+			converter: nonogram.ImageConverter{Threshold: 0.9},
+			img:       picture(arrow, 3, color.Gray{120}, color.Gray{160}),
+			n:         4,
+			m:         5,
+			expected:  "#####\n#####\n#####\n#####\n",
+			unique:    true,
+		},
+		{
+			name:     "downsampled",
+			img:      picture(arrow, 4, color.Black, color.White),
+			n:        2,
+			m:        5,
+			expected: ".###.\n#.#.#\n",
+			unique:   true,
+		},
+		{
+			name:     "transparent background",
+			img:      picture(arrow, 1, color.Black, color.Transparent),
+			n:        4,
+			m:        5,
+			expected: "..#..\n.###.\n#.#.#\n..#..\n",
+			unique:   true,
+		},
+		{
+			name:     "ambiguous",
+			img:      picture([]string{"#.", ".#"}, 1, color.Black, color.White),
+			n:        2,
+			m:        2,
+			expected: "#.\n.#\n",
+			unique:   false,
+		},
+		{
+			name:      "made unique",
+			converter: nonogram.ImageConverter{MakeUnique: true},
+			img:       picture([]string{"#.", ".#"}, 1, color.Black, color.White),
+			n:         2,
+			m:         2,
+			expected:  "..\n.#\n",
+			unique:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gram, unique, err := tt.converter.Convert(tt.img, tt.n, tt.m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, gram.String())
+			require.Equal(t, tt.unique, unique)
+		})
+	}
+}
+
+func TestImageConvertDither(t *testing.T) {
+	c := nonogram.ImageConverter{Threshold: 0.5, Dither: true}
+	gram, _, err := c.Convert(image.NewRGBA(image.Rect(0, 0, 8, 8)), 8, 8)
+	require.NoError(t, err)
+	// transparent picture is white
+	require.Equal(t, nonogram.New(8, 8).String(), gram.String())
+
+	gray := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := range 8 {
+		for x := range 8 {
+			gray.Set(x, y, color.Gray{128})
+		}
+	}
+
+	gram, _, err = c.Convert(gray, 8, 8)
+	require.NoError(t, err)
+
+	filled := 0
+	for i := range 8 {
+		for j := range 8 {
+			if gram.Get(i, j) {
+				filled++
+			}
+		}
+	}
+	// half of the cells are filled instead of none or all of them
+	require.InDelta(t, 32, filled, 2)
+}
+
+func TestImageConvertInvalidSize(t *testing.T) {
+	var c nonogram.ImageConverter
+
+	_, _, err := c.Convert(image.NewGray(image.Rect(0, 0, 4, 4)), 0, 4)
+	require.ErrorIs(t, err, nonogram.ErrInvalidSize)
+
+	_, _, err = c.Convert(image.NewGray(image.Rect(0, 0, 0, 0)), 4, 4)
+	require.ErrorIs(t, err, nonogram.ErrInvalidSize)
+}
+
+func TestImageConvertContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	img := picture([]string{"#.", ".#"}, 2, color.Black, color.White)
+	for _, c := range []nonogram.ImageConverter{{}, {MakeUnique: true}} {
+		gram, unique, err := c.ConvertContext(ctx, img, 2, 2)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, gram)
+		require.False(t, unique)
+	}
+}
@@ -0,0 +1,238 @@
+package nonogram
+
+// lineSolver finds cells of a single line which are forced by the clue
+// and by the cells that are already known.
+// It doesn't enumerate placements of blocks. Instead it computes which
+// prefixes and suffixes of the line can hold which blocks, packs blocks
+// as far to the left and to the right as possible to find the window
+// where every block may start, and intersects all placements inside
+// these windows. Solving a line takes O(len(line) * len(clue)) time.
+type lineSolver struct {
+	clue []int
+	// known cells of the line followed by one extra Blank cell,
+	// so every block can be followed by a blank cell
+	line []State
+	n    int
+	// blanks[i] is a count of Blank cells in line[:i]
+	blanks []int
+	// fwd[i][j] reports if first j blocks can be placed in line[:i]
+	// so that cell i-1 is blank (or i is 0)
+	fwd [][]bool
+	// bwd[i][j] reports if blocks from j-th to the last one can be placed
+	// in line[i:] when cell i-1 is blank (or i is 0)
+	bwd [][]bool
+}
+
+// SolveLine returns a copy of [line] where every cell that has the same
+// state in all placements of [clue] is set to that state, so it holds all
+// cells forced by the clue and by the cells that are already known.
+// Clue is a list of block lengths like in FillPattern.
+// Returns ErrNegativeBlock if some block is negative
+// and ErrContradiction if [clue] can't be placed in [line].
+func SolveLine(clue []int, line []State) ([]State, error) {
+	for _, size := range clue {
+		if size < 0 {
+			return nil, ErrNegativeBlock
+		}
+	}
+
+	return solveLine(clue, line)
+}
+
+// solveLine returns a copy of [line] where every cell that has the same
+// state in all placements of [clue] is set to that state.
+// It returns ErrContradiction if [clue] can't be placed in [line].
+func solveLine(clue []int, line []State) ([]State, error) {
+	ls := newLineSolver(clue, line)
+	if !ls.bwd[0][0] {
+		return nil, ErrContradiction
+	}
+
+	left, right := ls.pack()
+
+	canFill := make([]int, ls.n+1)
+	canBlank := make([]bool, ls.n+1)
+	for j, size := range ls.clue {
+		for start := left[j]; start <= right[j]; start++ {
+			if !ls.placeable(j, start) {
+				continue
+			}
+
+			// difference array, cells [start, start+size) can be filled
+			canFill[start]++
+			canFill[start+size]--
+			canBlank[start+size] = true
+		}
+	}
+
+	for i := range ls.n {
+		if canBlank[i] || ls.line[i] == Filled {
+			continue
+		}
+		for j := range len(ls.clue) + 1 {
+			if ls.fwd[i][j] && ls.bwd[i+1][j] {
+				canBlank[i] = true
+				break
+			}
+		}
+	}
+
+	res := make([]State, ls.n)
+	fills := 0
+	for i := range ls.n {
+		fills += canFill[i]
+		switch {
+		case fills > 0 && !canBlank[i]:
+			res[i] = Filled
+		case fills == 0 && canBlank[i]:
+			res[i] = Blank
+		default:
+			res[i] = Unknown
+		}
+	}
+
+	return res, nil
+}
+
+// overlapLine returns a line with cells forced by the simple overlap rule.
+// Cells covered by a block both in the leftmost and in the rightmost
+// packing are filled and cells no block can reach are blank, other cells
+// are Unknown even if solveLine could deduce them.
+// It returns ErrContradiction if [clue] can't be placed in [line].
+func overlapLine(clue []int, line []State) ([]State, error) {
+	ls := newLineSolver(clue, line)
+	if !ls.bwd[0][0] {
+		return nil, ErrContradiction
+	}
+
+	left, right := ls.pack()
+
+	res := make([]State, ls.n)
+	// difference array of blocks that can reach a cell
+	reach := make([]int, ls.n+1)
+	for j, size := range ls.clue {
+		for i := right[j]; i < left[j]+size; i++ {
+			res[i] = Filled
+		}
+		reach[left[j]]++
+		reach[right[j]+size]--
+	}
+
+	blocks := 0
+	for i := range ls.n {
+		blocks += reach[i]
+		if blocks == 0 {
+			res[i] = Blank
+		}
+	}
+
+	return res, nil
+}
+
+func newLineSolver(clue []int, line []State) *lineSolver {
+	ls := lineSolver{
+		n:    len(line),
+		line: make([]State, len(line)+1),
+	}
+
+	// zero length blocks are used to describe an empty line
+	for _, size := range clue {
+		if size > 0 {
+			ls.clue = append(ls.clue, size)
+		}
+	}
+
+	copy(ls.line, line)
+	ls.line[ls.n] = Blank
+
+	ls.blanks = make([]int, ls.n+2)
+	for i := range ls.n + 1 {
+		ls.blanks[i+1] = ls.blanks[i]
+		if ls.line[i] == Blank {
+			ls.blanks[i+1]++
+		}
+	}
+
+	k := len(ls.clue)
+	ls.fwd = make([][]bool, ls.n+2)
+	ls.bwd = make([][]bool, ls.n+2)
+	for i := range ls.n + 2 {
+		ls.fwd[i] = make([]bool, k+1)
+		ls.bwd[i] = make([]bool, k+1)
+	}
+
+	ls.fwd[0][0] = true
+	for i := range ls.n + 1 {
+		for j := range k + 1 {
+			if !ls.fwd[i][j] {
+				continue
+			}
+			if ls.line[i] != Filled {
+				ls.fwd[i+1][j] = true
+			}
+			if j < k && ls.fits(j, i) {
+				ls.fwd[i+ls.clue[j]+1][j+1] = true
+			}
+		}
+	}
+
+	ls.bwd[ls.n+1][k] = true
+	for i := ls.n; i >= 0; i-- {
+		for j := range k + 1 {
+			if ls.line[i] != Filled && ls.bwd[i+1][j] {
+				ls.bwd[i][j] = true
+			} else if j < k && ls.fits(j, i) && ls.bwd[i+ls.clue[j]+1][j+1] {
+				ls.bwd[i][j] = true
+			}
+		}
+	}
+
+	return &ls
+}
+
+// fits reports if j-th block can start at cell [start]
+// and be followed by a blank cell
+func (ls *lineSolver) fits(j, start int) bool {
+	end := start + ls.clue[j]
+	if end > ls.n {
+		return false
+	}
+
+	return ls.blanks[end]-ls.blanks[start] == 0 && ls.line[end] != Filled
+}
+
+// placeable reports if there is a placement of the clue
+// where j-th block starts at cell [start]
+func (ls *lineSolver) placeable(j, start int) bool {
+	return start >= 0 && ls.fwd[start][j] && ls.fits(j, start) && ls.bwd[start+ls.clue[j]+1][j+1]
+}
+
+// pack returns the leftmost and the rightmost start of every block
+// among all placements of the clue. It must be called only when
+// the clue can be placed in the line.
+func (ls *lineSolver) pack() ([]int, []int) {
+	k := len(ls.clue)
+	left := make([]int, k)
+	right := make([]int, k)
+
+	start := 0
+	for j := range k {
+		for !ls.placeable(j, start) {
+			start++
+		}
+		left[j] = start
+		start += ls.clue[j] + 1
+	}
+
+	start = ls.n
+	for j := k - 1; j >= 0; j-- {
+		start -= ls.clue[j]
+		for !ls.placeable(j, start) {
+			start--
+		}
+		right[j] = start
+		start--
+	}
+
+	return left, right
+}
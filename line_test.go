@@ -0,0 +1,156 @@
+package nonogram_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+// parseLine converts '#' to Filled, 'x' to Blank and other runes to Unknown
+func parseLine(s string) []nonogram.State {
+	line := make([]nonogram.State, 0, len(s))
+	for _, r := range s {
+		switch r {
+		case '#':
+			line = append(line, nonogram.Filled)
+		case 'x':
+			line = append(line, nonogram.Blank)
+		default:
+			line = append(line, nonogram.Unknown)
+		}
+	}
+
+	return line
+}
+
+func TestSolveLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		clue     []int
+		line     string
+		expected string
+	}{
+		{name: "overlap", clue: []int{4}, line: "......", expected: "..##.."},
+		{name: "full", clue: []int{2, 3}, line: "......", expected: "##x###"},
+		{name: "empty clue", clue: []int{0}, line: "....", expected: "xxxx"},
+		{name: "nil clue", clue: nil, line: "...", expected: "xxx"},
+		{name: "nothing forced", clue: []int{1}, line: "...", expected: "..."},
+		{name: "anchored by filled cell", clue: []int{3}, line: "#.....", expected: "###xxx"},
+		{name: "anchored by blank cell", clue: []int{2, 1}, line: "..x...", expected: "##x..."},
+		{name: "known cells are kept", clue: []int{1, 1}, line: "x#....", expected: "x#x..."},
+		{name: "empty line", clue: []int{0}, line: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := parseLine(tt.line)
+			solved, err := nonogram.SolveLine(tt.clue, line)
+			require.NoError(t, err)
+			require.Equal(t, parseLine(tt.expected), solved)
+			// the line passed to SolveLine is not changed
+			require.Equal(t, parseLine(tt.line), line)
+		})
+	}
+}
+
+func TestSolveLineErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		clue     []int
+		line     string
+		expected error
+	}{
+		{name: "too long", clue: []int{2, 2}, line: "....", expected: nonogram.ErrContradiction},
+		{name: "blocked by blank cell", clue: []int{3}, line: ".x.x.", expected: nonogram.ErrContradiction},
+		{name: "too many filled cells", clue: []int{1}, line: "#.#", expected: nonogram.ErrContradiction},
+		{name: "filled cell in empty line", clue: []int{0}, line: "..#", expected: nonogram.ErrContradiction},
+		{name: "negative block", clue: []int{-1}, line: "...", expected: nonogram.ErrNegativeBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := nonogram.SolveLine(tt.clue, parseLine(tt.line))
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+// TestSolveLineRandom compares SolveLine with intersection of all
+// fillings of the line that match the clue
+func TestSolveLineRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for range 2000 {
+		n := 1 + r.Intn(10)
+
+		var clue []int
+		for size := 0; size < n; {
+			if r.Intn(3) == 0 {
+				size++
+				continue
+			}
+			block := 1 + r.Intn(3)
+			if size+block > n {
+				break
+			}
+			clue = append(clue, block)
+			size += block + 1
+		}
+
+		line := make([]nonogram.State, n)
+		for i := range line {
+			line[i] = nonogram.State(r.Intn(3)) * nonogram.State(r.Intn(2))
+		}
+
+		expected := make([]nonogram.State, n)
+		found := false
+		for mask := range 1 << n {
+			filling := make([]nonogram.State, n)
+			var blocks []int
+			matches := true
+			for i := range n {
+				filling[i] = nonogram.Blank
+				if mask&(1<<i) != 0 {
+					filling[i] = nonogram.Filled
+					if i == 0 || mask&(1<<(i-1)) == 0 {
+						blocks = append(blocks, 0)
+					}
+					blocks[len(blocks)-1]++
+				}
+				if line[i] != nonogram.Unknown && line[i] != filling[i] {
+					matches = false
+				}
+			}
+			if !matches || len(blocks) != len(clue) {
+				continue
+			}
+			for k := range blocks {
+				if blocks[k] != clue[k] {
+					matches = false
+				}
+			}
+			if !matches {
+				continue
+			}
+
+			for i := range n {
+				if !found {
+					expected[i] = filling[i]
+				} else if expected[i] != filling[i] {
+					expected[i] = nonogram.Unknown
+				}
+			}
+			found = true
+		}
+
+		solved, err := nonogram.SolveLine(clue, line)
+		if !found {
+			require.ErrorIs(t, err, nonogram.ErrContradiction, "clue %v line %v", clue, line)
+			continue
+		}
+		require.NoError(t, err, "clue %v line %v", clue, line)
+		require.Equal(t, expected, solved, "clue %v line %v", clue, line)
+	}
+}
@@ -2,6 +2,8 @@ package nonogram
 
 import (
 	"errors"
+	"image/png"
+	"io"
 	"strings"
 )
 
@@ -54,6 +56,27 @@ func (n *Nonogram) PrettyStringCaged(cage int) string {
 	return n.toString('█', ' ', cage)
 }
 
+// StringWithClues shows the grid with its clues along the top and the left
+// like in the FillPattern diagram, cage=0 means no cage
+func (n *Nonogram) StringWithClues(cage int) string {
+	return n.cluedString('#', '.', cage)
+}
+
+func (n *Nonogram) PrettyStringWithClues(cage int) string {
+	return n.cluedString('█', ' ', cage)
+}
+
+func (n *Nonogram) cluedString(fill, blank rune, cage int) string {
+	rows, columns := n.FillPatterns()
+
+	return cluedString(rows, columns, func(i, j int) rune {
+		if n.Get(i, j) {
+			return fill
+		}
+		return blank
+	}, cage)
+}
+
 func EncodedSize(n, m int) int {
 	return (n*m + numBits - 1) / numBits
 }
@@ -94,13 +117,33 @@ func (n *Nonogram) toString(fill, blank rune, cage int) string {
 	return b.String()
 }
 
+// SavePNG paints filled cells in black and other cells in white
+func (n *Nonogram) SavePNG(name string, scale int) error {
+	return savePNG(name, func(w io.Writer) error {
+		return n.EncodePNG(w, PNGOptions{Scale: scale})
+	})
+}
+
+// EncodePNG writes the grid to [w] as png image drawn with [opts],
+// cells which are not filled have color of Blank
+func (n *Nonogram) EncodePNG(w io.Writer, opts PNGOptions) error {
+	img := drawPNG(n.n, n.m, func(i, j int) State {
+		if n.Get(i, j) {
+			return Filled
+		}
+		return Blank
+	}, opts)
+
+	return png.Encode(w, img)
+}
+
 func (n *Nonogram) Fill(i, j int) {
 	if !(0 <= i && i < n.n) || !(0 <= j && j < n.m) {
 		return
 	}
 
-	numberIdx := (i*n.n + j) / numBits
-	bit := (i*n.n + j) % numBits
+	numberIdx := (i*n.m + j) / numBits
+	bit := (i*n.m + j) % numBits
 
 	n.grid[numberIdx] |= (1 << bit)
 }
@@ -110,8 +153,8 @@ func (n *Nonogram) Clear(i, j int) {
 		return
 	}
 
-	numberIdx := (i*n.n + j) / numBits
-	bit := (i*n.n + j) % numBits
+	numberIdx := (i*n.m + j) / numBits
+	bit := (i*n.m + j) % numBits
 
 	n.grid[numberIdx] &= (1<<numBits - 1) ^ (1 << bit)
 }
@@ -121,8 +164,8 @@ func (n *Nonogram) Get(i, j int) bool {
 		return false
 	}
 
-	numberIdx := (i*n.n + j) / numBits
-	bit := (i*n.n + j) % numBits
+	numberIdx := (i*n.m + j) / numBits
+	bit := (i*n.m + j) % numBits
 
 	return (n.grid[numberIdx]>>bit)&1 == 1
 }
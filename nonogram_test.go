@@ -113,6 +113,51 @@ func TestString(t *testing.T) {
 #.#...#.#####.#
 ##...######.#.#
 .#####..#..####
+`,
+		},
+		{
+			name: "wide size 1",
+			n:    3,
+			m:    5,
+			grid: []uint64{17485029721327973432},
+			expected: `...##
+#....
+#.##.
+`,
+		},
+		{
+			name: "wide size 2",
+			n:    2,
+			m:    9,
+			grid: []uint64{890727360438182992},
+			expected: `....#.#..
+.#..#.###
+`,
+		},
+		{
+			name: "tall size 1",
+			n:    5,
+			m:    3,
+			grid: []uint64{7283207964119141687},
+			expected: `###
+.##
+..#
+.##
+...
+`,
+		},
+		{
+			name: "tall size 2",
+			n:    7,
+			m:    12,
+			grid: []uint64{15149836622520594227, 1736392818365009963},
+			expected: `##..##..####
+.#..##.#...#
+.#..#.....#.
+.#.....#....
+######...#..
+#.####.#.#..
+....#..#####
 `,
 		},
 	}
@@ -372,6 +417,73 @@ func TestPrettyStringCaged(t *testing.T) {
 	}
 }
 
+func TestStringWithClues(t *testing.T) {
+	gram := nonogram.New(3, 4)
+	gram.Fill(0, 0)
+	gram.Fill(0, 1)
+	gram.Fill(0, 3)
+	gram.Fill(2, 1)
+	gram.Fill(2, 2)
+	gram.Fill(2, 3)
+
+	tests := []struct {
+		name     string
+		pretty   bool
+		cage     int
+		expected string
+	}{
+		{
+			name: "no cage",
+			cage: 0,
+			expected: "" +
+				"      1 1\n" +
+				"     1111\n" +
+				"    ┌────┐\n" +
+				" 2 1│##.#│\n" +
+				"   0│....│\n" +
+				"   3│.###│\n" +
+				"    └────┘\n",
+		},
+		{
+			name: "caged",
+			cage: 2,
+			expected: "" +
+				"      1  1\n" +
+				"     11 11\n" +
+				"    ┌──┬──┐\n" +
+				" 2 1│##│.#│\n" +
+				"   0│..│..│\n" +
+				"    ├──┼──┤\n" +
+				"   3│.#│##│\n" +
+				"    └──┴──┘\n",
+		},
+		{
+			name:   "pretty caged",
+			pretty: true,
+			cage:   2,
+			expected: "" +
+				"      1  1\n" +
+				"     11 11\n" +
+				"    ┌──┬──┐\n" +
+				" 2 1│██│ █│\n" +
+				"   0│  │  │\n" +
+				"    ├──┼──┤\n" +
+				"   3│ █│██│\n" +
+				"    └──┴──┘\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pretty {
+				require.Equal(t, tt.expected, gram.PrettyStringWithClues(tt.cage))
+			} else {
+				require.Equal(t, tt.expected, gram.StringWithClues(tt.cage))
+			}
+		})
+	}
+}
+
 func TestFill(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -462,23 +574,65 @@ func TestFill(t *testing.T) {
 }
 
 func TestFillClear(t *testing.T) {
-	gram := nonogram.New(15, 15)
-	var board [15][15]int
-	for range 10000 {
-		i := rand.Int() % 15
-		j := rand.Int() % 15
-		if rand.Int()%2 == 0 {
-			gram.Fill(i, j)
-			board[i][j] = 1
-		} else {
-			gram.Clear(i, j)
-			board[i][j] = 0
-		}
+	sizes := []struct {
+		n, m int
+	}{
+		{15, 15},
+		{1, 20},
+		{20, 1},
+		{3, 17},
+		{17, 3},
+		{9, 70},
+		{70, 9},
 	}
 
-	for i := range 15 {
-		for j := range 15 {
-			require.Equal(t, board[i][j] == 1, gram.Get(i, j))
+	for _, size := range sizes {
+		gram := nonogram.New(size.n, size.m)
+		board := make([][]bool, size.n)
+		for i := range size.n {
+			board[i] = make([]bool, size.m)
+		}
+
+		for range 10000 {
+			i := rand.Int() % size.n
+			j := rand.Int() % size.m
+			if rand.Int()%2 == 0 {
+				gram.Fill(i, j)
+				board[i][j] = true
+			} else {
+				gram.Clear(i, j)
+				board[i][j] = false
+			}
+		}
+
+		for i := range size.n {
+			for j := range size.m {
+				require.Equal(t, board[i][j], gram.Get(i, j))
+			}
 		}
 	}
 }
+
+func TestFromGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		n, m     int
+		grid     []uint64
+		expected error
+	}{
+		{name: "square", n: 8, m: 8, grid: []uint64{0}},
+		{name: "wide", n: 2, m: 40, grid: []uint64{0, 0}},
+		{name: "tall", n: 40, m: 2, grid: []uint64{0, 0}},
+		{name: "too short grid", n: 2, m: 40, grid: []uint64{0}, expected: nonogram.ErrInvalidGrid},
+		{name: "too long grid", n: 40, m: 1, grid: []uint64{0, 0}, expected: nonogram.ErrInvalidGrid},
+		{name: "zero rows", n: 0, m: 5, grid: []uint64{}, expected: nonogram.ErrInvalidSize},
+		{name: "negative columns", n: 5, m: -1, grid: []uint64{}, expected: nonogram.ErrInvalidSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := nonogram.FromGrid(tt.n, tt.m, tt.grid)
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
@@ -216,6 +216,42 @@ func TestFillPattern(t *testing.T) {
 				{3, 1, 2, 2, 1},
 			},
 		},
+		{
+			name: "wide",
+			n:    3,
+			m:    5,
+			grid: []uint64{17485029721327973432},
+			expectedRowsPattern: nonogram.FillPattern{
+				{2},
+				{1},
+				{1, 2},
+			},
+			expectedColumnsPattern: nonogram.FillPattern{
+				{2},
+				{0},
+				{1},
+				{1, 1},
+				{1},
+			},
+		},
+		{
+			name: "tall",
+			n:    5,
+			m:    3,
+			grid: []uint64{7283207964119141687},
+			expectedRowsPattern: nonogram.FillPattern{
+				{3},
+				{2},
+				{1},
+				{2},
+				{0},
+			},
+			expectedColumnsPattern: nonogram.FillPattern{
+				{1},
+				{2, 1},
+				{4},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,120 @@
+package nonogram
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"os"
+)
+
+const defaultPNGScale = 10
+
+// StatePalette is used to draw cells when PNGOptions.Palette is not set.
+// It's indexed by State: Unknown cells are red, Filled are black
+// and Blank are white.
+var StatePalette = color.Palette{
+	Unknown: color.RGBA{255, 0, 0, 255},
+	Filled:  color.RGBA{0, 0, 0, 255},
+	Blank:   color.RGBA{255, 255, 255, 255},
+}
+
+// PNGOptions tell how to draw png images. Zero value draws
+// 10 pixels wide cells in StatePalette without any lines.
+type PNGOptions struct {
+	// width of a cell in pixels, 10 is used when Scale <= 0
+	Scale int
+	// colors of cells indexed by State,
+	// StatePalette is used when it has less than 3 colors
+	Palette color.Palette
+	// draw 1 pixel wide lines between cells and around the grid
+	GridLines bool
+	// draw lines every Cage cells and around the grid like in StringCaged
+	// over grid lines, 0 means no cage and the border has GridColor
+	Cage int
+	// colors of grid and cage lines, gray and black are used when they're nil
+	GridColor color.Color
+	CageColor color.Color
+}
+
+// drawPNG draws the grid of n x m cells, [cell] returns the state of a cell
+func drawPNG(n, m int, cell func(i, j int) State, opts PNGOptions) *image.RGBA {
+	palette := opts.Palette
+	if len(palette) < len(StatePalette) {
+		palette = StatePalette
+	}
+
+	return drawCells(n, m, func(i, j int) color.Color {
+		return palette[cell(i, j)]
+	}, opts)
+}
+
+// drawCells draws the grid of n x m cells, [cell] returns the color
+// of a cell and opts.Palette is not used.
+// Lines are drawn over the first pixels of cells, so the image is only
+// 1 pixel larger with them.
+func drawCells(n, m int, cell func(i, j int) color.Color, opts PNGOptions) *image.RGBA {
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = defaultPNGScale
+	}
+
+	gridColor, cageColor := opts.GridColor, opts.CageColor
+	if gridColor == nil {
+		gridColor = color.RGBA{200, 200, 200, 255}
+	}
+	if cageColor == nil {
+		cageColor = color.Black
+	}
+
+	border := 0
+	if opts.GridLines || opts.Cage > 0 {
+		border = 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, m*scale+border, n*scale+border))
+	for i := range n {
+		for j := range m {
+			fill(img, image.Rect(j*scale, i*scale, (j+1)*scale, (i+1)*scale), cell(i, j))
+		}
+	}
+
+	if border == 0 {
+		return img
+	}
+
+	for _, thick := range []bool{false, true} {
+		for i := range n + 1 {
+			isCage := opts.Cage > 0 && (i == 0 || i == n || i%opts.Cage == 0)
+			if thick && isCage || !thick && opts.GridLines {
+				fill(img, image.Rect(0, i*scale, m*scale+1, i*scale+1), lineColor(thick, gridColor, cageColor))
+			}
+		}
+		for j := range m + 1 {
+			isCage := opts.Cage > 0 && (j == 0 || j == m || j%opts.Cage == 0)
+			if thick && isCage || !thick && opts.GridLines {
+				fill(img, image.Rect(j*scale, 0, j*scale+1, n*scale+1), lineColor(thick, gridColor, cageColor))
+			}
+		}
+	}
+
+	return img
+}
+
+// savePNG creates file [name] and writes the image there with [encode]
+func savePNG(name string, encode func(w io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := encode(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
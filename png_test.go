@@ -0,0 +1,107 @@
+package nonogram_test
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolverEncodePNG(t *testing.T) {
+	rows := nonogram.FillPattern{{1}, {1}}
+	columns := nonogram.FillPattern{{2}, {0}}
+
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(rows, columns))
+
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	gray := color.RGBA{200, 200, 200, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+
+	tests := []struct {
+		name   string
+		opts   nonogram.PNGOptions
+		width  int
+		height int
+		// expected colors of pixels
+		pixels map[[2]int]color.RGBA
+	}{
+		{
+			name:   "default",
+			opts:   nonogram.PNGOptions{},
+			width:  20,
+			height: 20,
+			pixels: map[[2]int]color.RGBA{{0, 0}: black, {15, 5}: white, {5, 15}: black},
+		},
+		{
+			name:   "palette",
+			opts:   nonogram.PNGOptions{Scale: 2, Palette: color.Palette{red, green, blue}},
+			width:  4,
+			height: 4,
+			pixels: map[[2]int]color.RGBA{{0, 0}: green, {3, 0}: blue},
+		},
+		{
+			name:   "grid lines",
+			opts:   nonogram.PNGOptions{Scale: 5, GridLines: true},
+			width:  11,
+			height: 11,
+			pixels: map[[2]int]color.RGBA{{5, 2}: gray, {2, 5}: gray, {10, 10}: gray, {0, 3}: gray, {2, 2}: black},
+		},
+		{
+			name:   "cage",
+			opts:   nonogram.PNGOptions{Scale: 5, Cage: 1, GridLines: true, CageColor: blue},
+			width:  11,
+			height: 11,
+			pixels: map[[2]int]color.RGBA{{5, 2}: blue, {0, 0}: blue, {7, 2}: white},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, s.EncodePNG(&b, tt.opts))
+
+			img, err := png.Decode(&b)
+			require.NoError(t, err)
+			require.Equal(t, tt.width, img.Bounds().Dx())
+			require.Equal(t, tt.height, img.Bounds().Dy())
+			for p, expected := range tt.pixels {
+				require.Equal(t, expected, color.RGBAModel.Convert(img.At(p[0], p[1])), "pixel %v", p)
+			}
+		})
+	}
+}
+
+func TestSolverEncodePNGHex(t *testing.T) {
+	g := nonogram.Hex{Side: 2}
+	clues := nonogram.Clues(g, func(cell int) bool { return cell == 0 })
+
+	var s nonogram.Solver
+	require.NoError(t, s.SolveGeometry(context.Background(), g, clues))
+
+	var b bytes.Buffer
+	require.ErrorIs(t, s.EncodePNG(&b, nonogram.PNGOptions{}), nonogram.ErrNotRectangular)
+}
+
+func TestNonogramEncodePNG(t *testing.T) {
+	n := nonogram.New(1, 2)
+	n.Fill(0, 1)
+
+	var b bytes.Buffer
+	require.NoError(t, n.EncodePNG(&b, nonogram.PNGOptions{Scale: 3}))
+
+	img, err := png.Decode(&b)
+	require.NoError(t, err)
+	require.Equal(t, 6, img.Bounds().Dx())
+	require.Equal(t, 3, img.Bounds().Dy())
+	require.Equal(t, color.RGBA{255, 255, 255, 255}, color.RGBAModel.Convert(img.At(1, 1)))
+	require.Equal(t, color.RGBA{0, 0, 0, 255}, color.RGBAModel.Convert(img.At(4, 1)))
+}
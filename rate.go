@@ -0,0 +1,139 @@
+package nonogram
+
+// Technique needed to solve a puzzle, from the easiest to the hardest
+type Technique int
+
+const (
+	// every line is solved with simple overlap of the leftmost
+	// and the rightmost placements of blocks
+	TechniqueOverlap Technique = iota + 1
+	// some lines need intersection of all placements of blocks,
+	// which finds cells forced by gaps and edges of the line
+	TechniqueIntersection
+	// some cells need probing or a guess which is refuted
+	// by propagation without guessing any further
+	TechniqueProbing
+	// nested guesses are needed
+	TechniqueSearch
+)
+
+func (t Technique) String() string {
+	switch t {
+	case TechniqueOverlap:
+		return "overlap"
+	case TechniqueIntersection:
+		return "intersection"
+	case TechniqueProbing:
+		return "probing"
+	case TechniqueSearch:
+		return "search"
+	default:
+		return "unknown technique"
+	}
+}
+
+// Tier of puzzle difficulty
+type Tier int
+
+const (
+	Easy Tier = iota + 1
+	Medium
+	Hard
+)
+
+func (t Tier) String() string {
+	switch t {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	default:
+		return "unknown tier"
+	}
+}
+
+// Rating describes how much effort the solver spent on a puzzle
+type Rating struct {
+	// the hardest technique which was required
+	Technique Technique
+	// propagation passes which deduced at least one cell, see Stats.Passes
+	Passes int
+	// deductions of a line which needed intersection of all placements
+	Intersections int
+	// probes which deduced at least one cell
+	Probes     int
+	Guesses    int
+	Backtracks int
+	// maximal number of nested guesses
+	Depth int
+	// false if the puzzle has more than one solution
+	Unique bool
+	// Passes + Intersections + 5 * Probes + 10 * (Guesses + Backtracks)
+	Score int
+	// Easy when Score < 20, Medium when Score < 50 and Hard otherwise.
+	// Puzzles which need probing or guessing are always Hard,
+	// even if they are solved without guesses.
+	Tier Tier
+}
+
+// Rate solves the puzzle and measures the effort. Solving continues
+// after the first solution is found to prove it's unique, so guesses
+// made to rule out other solutions make puzzle harder.
+// Returns ErrContradiction if puzzle has no solution.
+func Rate(rows FillPattern, columns FillPattern) (Rating, error) {
+	var r Rating
+
+	s := Solver{OnStep: r.record}
+	solutions, err := s.CountSolutions(rows, columns, 2)
+	if err != nil {
+		return Rating{}, err
+	}
+
+	if len(solutions) == 0 {
+		return Rating{}, ErrContradiction
+	}
+
+	stats := s.Stats()
+	r.Passes = stats.Passes
+	r.Guesses = stats.Guesses
+	r.Backtracks = stats.Backtracks
+	r.Unique = len(solutions) == 1
+
+	switch {
+	case r.Depth > 1:
+		r.Technique = TechniqueSearch
+	case r.Depth == 1 || r.Probes > 0:
+		r.Technique = TechniqueProbing
+	case r.Intersections > 0:
+		r.Technique = TechniqueIntersection
+	default:
+		r.Technique = TechniqueOverlap
+	}
+
+	r.Score = r.Passes + r.Intersections + 5*r.Probes + 10*(r.Guesses+r.Backtracks)
+	switch {
+	case r.Technique >= TechniqueProbing || r.Score >= 50:
+		r.Tier = Hard
+	case r.Score >= 20:
+		r.Tier = Medium
+	default:
+		r.Tier = Easy
+	}
+
+	return r, nil
+}
+
+func (r *Rating) record(step Step) {
+	switch step.Kind {
+	case Deduction:
+		if step.Rule == RuleIntersection {
+			r.Intersections++
+		}
+	case Probe:
+		r.Probes++
+	case Guess:
+		r.Depth = max(r.Depth, step.Depth)
+	}
+}
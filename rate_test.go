@@ -0,0 +1,112 @@
+package nonogram_test
+
+import (
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     nonogram.FillPattern
+		columns  nonogram.FillPattern
+		expected nonogram.Rating
+	}{
+		{
+			name:    "overlap",
+			rows:    nonogram.FillPattern{{1}, {1, 1}, {1}},
+			columns: nonogram.FillPattern{{1}, {1, 1}, {1}},
+			expected: nonogram.Rating{
+				Technique: nonogram.TechniqueOverlap,
+				Passes:    1,
+				Unique:    true,
+				Score:     1,
+				Tier:      nonogram.Easy,
+			},
+		},
+		{
+			name:    "intersection",
+			rows:    nonogram.FillPattern{{2, 2}, {1, 3}, {1, 1, 1}, {1, 1, 2}, {1, 1, 1, 1}, {3, 1}},
+			columns: nonogram.FillPattern{{3, 1}, {1}, {1, 3}, {3, 1}, {2, 3}, {0}, {2, 1}, {2}},
+			expected: nonogram.Rating{
+				Technique:     nonogram.TechniqueIntersection,
+				Passes:        3,
+				Intersections: 1,
+				Unique:        true,
+				Score:         4,
+				Tier:          nonogram.Easy,
+			},
+		},
+		{
+			name:    "probing without guesses",
+			rows:    nonogram.FillPattern{{1, 1}, {1}, {3}, {2}},
+			columns: nonogram.FillPattern{{2}, {1}, {2}, {2}, {1}},
+			expected: nonogram.Rating{
+				Technique: nonogram.TechniqueProbing,
+				Passes:    1,
+				Probes:    1,
+				Unique:    true,
+				Score:     6,
+				Tier:      nonogram.Hard,
+			},
+		},
+		{
+			name:    "probing",
+			rows:    nonogram.FillPattern{{1}, {1}},
+			columns: nonogram.FillPattern{{1}, {1}},
+			expected: nonogram.Rating{
+				Technique:  nonogram.TechniqueProbing,
+				Passes:     2,
+				Guesses:    2,
+				Backtracks: 1,
+				Depth:      1,
+				Score:      32,
+				Tier:       nonogram.Hard,
+			},
+		},
+		{
+			name:    "search",
+			rows:    nonogram.FillPattern{{1}, {1}, {1}},
+			columns: nonogram.FillPattern{{1}, {1}, {1}},
+			expected: nonogram.Rating{
+				Technique:  nonogram.TechniqueSearch,
+				Passes:     3,
+				Guesses:    3,
+				Backtracks: 1,
+				Depth:      2,
+				Score:      43,
+				Tier:       nonogram.Hard,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rating, err := nonogram.Rate(tt.rows, tt.columns)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rating)
+		})
+	}
+}
+
+func TestRateNoSolution(t *testing.T) {
+	_, err := nonogram.Rate(nonogram.FillPattern{{1, 1}, {0}}, nonogram.FillPattern{{1}, {1}, {0}})
+	require.ErrorIs(t, err, nonogram.ErrContradiction)
+}
+
+func TestRateGenerated(t *testing.T) {
+	g := nonogram.Generator{Seed: 3}
+	for range 5 {
+		gram, err := g.Generate(15, 15)
+		require.NoError(t, err)
+
+		rating, err := nonogram.Rate(gram.FillPatterns())
+		require.NoError(t, err)
+		require.True(t, rating.Unique)
+		require.Zero(t, rating.Guesses)
+		require.LessOrEqual(t, rating.Technique, nonogram.TechniqueIntersection)
+	}
+}
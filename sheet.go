@@ -0,0 +1,235 @@
+package nonogram
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// digitFont is a 3x5 pixel font used to draw clues on png sheets
+var digitFont = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// sheetCage is the number of cells between thick lines of png and svg sheets
+const sheetCage = 5
+
+// Sheet returns the unsolved puzzle with clues along the top and the left
+// of the grid, the same way as in the FillPattern diagram.
+// Clues of 10 and more make every column wider.
+func (p *Puzzle) Sheet() string {
+	return cluedString(p.Rows, p.Columns, func(i, j int) rune { return '.' }, 0)
+}
+
+// cluedString draws clues around the grid, [cell] returns the rune of a cell.
+// cage=0 means no cage.
+func cluedString(rows FillPattern, columns FillPattern, cell func(i, j int) rune, cage int) string {
+	var b strings.Builder
+
+	rowWidth := 0
+	for _, clue := range rows {
+		rowWidth = max(rowWidth, len(joinClue(clue, " "))+1)
+	}
+
+	columnWidth, height := 1, 0
+	for _, clue := range columns {
+		height = max(height, len(clue))
+		for _, size := range clue {
+			if len(strconv.Itoa(size)) > 1 {
+				columnWidth = max(columnWidth, len(strconv.Itoa(size))+1)
+			}
+		}
+	}
+
+	// separator reports if cage line goes before the k-th cell
+	separator := func(k int) bool {
+		return cage != 0 && k != 0 && k%cage == 0
+	}
+
+	horizontal := func(left, cross, right rune) {
+		fmt.Fprintf(&b, "%*s%c", rowWidth, "", left)
+		for j := range columns {
+			if separator(j) {
+				b.WriteRune(cross)
+			}
+			b.WriteString(strings.Repeat("─", columnWidth))
+		}
+		b.WriteRune(right)
+		b.WriteRune('\n')
+	}
+
+	for k := range height {
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", rowWidth+1))
+		for j, clue := range columns {
+			if separator(j) {
+				line.WriteRune(' ')
+			}
+
+			block := ""
+			if i := k - (height - len(clue)); i >= 0 {
+				block = strconv.Itoa(clue[i])
+			}
+			fmt.Fprintf(&line, "%*s", columnWidth, block)
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteRune('\n')
+	}
+
+	horizontal('┌', '┬', '┐')
+	for i, clue := range rows {
+		if separator(i) {
+			horizontal('├', '┼', '┤')
+		}
+
+		fmt.Fprintf(&b, "%*s│", rowWidth, joinClue(clue, " "))
+		for j := range columns {
+			if separator(j) {
+				b.WriteRune('│')
+			}
+			fmt.Fprintf(&b, "%*c", columnWidth, cell(i, j))
+		}
+		b.WriteString("│\n")
+	}
+	horizontal('└', '┴', '┘')
+
+	return b.String()
+}
+
+// SaveSheetPNG draws the unsolved puzzle with clues to png file,
+// every cell and every clue number is [scale] pixels wide
+func (p *Puzzle) SaveSheetPNG(name string, scale int) error {
+	return savePNG(name, func(w io.Writer) error {
+		return p.EncodeSheetPNG(w, scale)
+	})
+}
+
+// EncodeSheetPNG is like SaveSheetPNG but writes the image to [w]
+func (p *Puzzle) EncodeSheetPNG(w io.Writer, scale int) error {
+	return png.Encode(w, p.sheetImage(scale))
+}
+
+func (p *Puzzle) sheetImage(scale int) *image.RGBA {
+	left, top := p.clueSize()
+	n, m := len(p.Rows), len(p.Columns)
+
+	img := image.NewRGBA(image.Rect(0, 0, (left+m)*scale+1, (top+n)*scale+1))
+	fill(img, img.Bounds(), color.White)
+
+	// numbers of every clue have the same size that fits into a cell
+	digits := 1
+	for _, pattern := range []FillPattern{p.Rows, p.Columns} {
+		for _, clue := range pattern {
+			for _, size := range clue {
+				digits = max(digits, len(strconv.Itoa(size)))
+			}
+		}
+	}
+	pixel := max(1, min((scale-2)/(4*digits-1), (scale-2)/5))
+
+	for i, clue := range p.Rows {
+		for k, size := range clue {
+			drawNumber(img, size, left-len(clue)+k, top+i, scale, pixel)
+		}
+	}
+	for j, clue := range p.Columns {
+		for k, size := range clue {
+			drawNumber(img, size, left+j, top-len(clue)+k, scale, pixel)
+		}
+	}
+
+	gray := color.RGBA{200, 200, 200, 255}
+	for _, thick := range []bool{false, true} {
+		for i := range n + 1 {
+			if thick == (i%sheetCage == 0 || i == n) {
+				y := (top + i) * scale
+				fill(img, image.Rect(left*scale, y, (left+m)*scale+1, y+1), lineColor(thick, gray, color.Black))
+			}
+		}
+		for j := range m + 1 {
+			if thick == (j%sheetCage == 0 || j == m) {
+				x := (left + j) * scale
+				fill(img, image.Rect(x, top*scale, x+1, (top+n)*scale+1), lineColor(thick, gray, color.Black))
+			}
+		}
+	}
+
+	return img
+}
+
+// drawNumber draws [number] centered in the cell (column, row)
+func drawNumber(img *image.RGBA, number int, column, row, scale, pixel int) {
+	digits := strconv.Itoa(number)
+	width := (4*len(digits) - 1) * pixel
+	x := column*scale + (scale-width+1)/2
+	y := row*scale + (scale-5*pixel+1)/2
+
+	for _, d := range digits {
+		for dy, glyphRow := range digitFont[d-'0'] {
+			for dx, dot := range glyphRow {
+				if dot == '#' {
+					px, py := x+dx*pixel, y+dy*pixel
+					fill(img, image.Rect(px, py, px+pixel, py+pixel), color.Black)
+				}
+			}
+		}
+		x += 4 * pixel
+	}
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+func lineColor(isThick bool, thin, thick color.Color) color.Color {
+	if isThick {
+		return thick
+	}
+
+	return thin
+}
+
+// WriteSheetSVG writes the unsolved puzzle with clues as svg image,
+// every cell is [scale] pixels wide
+func (p *Puzzle) WriteSheetSVG(w io.Writer, scale int) error {
+	g := svgGrid{
+		n:       len(p.Rows),
+		m:       len(p.Columns),
+		rows:    p.Rows,
+		columns: p.Columns,
+		scale:   scale,
+		cage:    sheetCage,
+	}
+
+	return g.write(w)
+}
+
+// clueSize returns the maximal number of blocks in rows and in columns
+func (p *Puzzle) clueSize() (int, int) {
+	left, top := 0, 0
+	for _, clue := range p.Rows {
+		left = max(left, len(clue))
+	}
+	for _, clue := range p.Columns {
+		top = max(top, len(clue))
+	}
+
+	return left, top
+}
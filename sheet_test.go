@@ -0,0 +1,103 @@
+package nonogram_test
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheet(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     nonogram.FillPattern
+		columns  nonogram.FillPattern
+		expected string
+	}{
+		{
+			name:    "diagram",
+			rows:    nonogram.FillPattern{{0}, {4}, {6}, {6}, {2, 2}, {2, 2}, {4}, {2}, {2}, {2}, {0}},
+			columns: nonogram.FillPattern{{0}, {9}, {9}, {2, 2}, {2, 2}, {4}, {4}, {0}},
+			expected: "" +
+				"        22\n" +
+				"     09922440\n" +
+				"    ┌────────┐\n" +
+				"   0│........│\n" +
+				"   4│........│\n" +
+				"   6│........│\n" +
+				"   6│........│\n" +
+				" 2 2│........│\n" +
+				" 2 2│........│\n" +
+				"   4│........│\n" +
+				"   2│........│\n" +
+				"   2│........│\n" +
+				"   2│........│\n" +
+				"   0│........│\n" +
+				"    └────────┘\n",
+		},
+		{
+			name:    "wide clues",
+			rows:    nonogram.FillPattern{{2}, {1}},
+			columns: nonogram.FillPattern{{10}, {1, 1}},
+			expected: "" +
+				"        1\n" +
+				"    10  1\n" +
+				"  ┌──────┐\n" +
+				" 2│  .  .│\n" +
+				" 1│  .  .│\n" +
+				"  └──────┘\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := nonogram.Puzzle{Rows: tt.rows, Columns: tt.columns}
+			require.Equal(t, tt.expected, p.Sheet())
+		})
+	}
+}
+
+func TestSaveSheetPNG(t *testing.T) {
+	p := nonogram.Puzzle{Rows: nonogram.FillPattern{{1, 1}, {3}}, Columns: nonogram.FillPattern{{2}, {1}, {2}}}
+
+	name := filepath.Join(t.TempDir(), "sheet.png")
+	require.NoError(t, p.SaveSheetPNG(name, 10))
+
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	// 2 cells of row clues and 3 columns, 1 cell of column clues and 2 rows
+	require.Equal(t, 51, img.Bounds().Dx())
+	require.Equal(t, 31, img.Bounds().Dy())
+
+	black := color.RGBAModel.Convert(color.Black)
+	white := color.RGBAModel.Convert(color.White)
+	// top left corner of the clue cell is white and the grid border is black
+	require.Equal(t, white, color.RGBAModel.Convert(img.At(0, 0)))
+	require.Equal(t, black, color.RGBAModel.Convert(img.At(20, 10)))
+	require.Equal(t, black, color.RGBAModel.Convert(img.At(50, 30)))
+	// middle of the digit 3 of the last row
+	require.Equal(t, black, color.RGBAModel.Convert(img.At(15, 25)))
+}
+
+func TestWriteSheetSVG(t *testing.T) {
+	p := nonogram.Puzzle{Rows: nonogram.FillPattern{{1, 1}, {3}}, Columns: nonogram.FillPattern{{2}, {1}, {2}}}
+
+	var b strings.Builder
+	require.NoError(t, p.WriteSheetSVG(&b, 10))
+
+	svg := b.String()
+	require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="52" height="32"`))
+	require.Equal(t, 6, strings.Count(svg, "<text"))
+	require.Contains(t, svg, `<text x="16.0" y="26.0">3</text>`)
+	require.Equal(t, 7, strings.Count(svg, "<line"))
+}
@@ -1,12 +1,15 @@
 package nonogram
 
 import (
+	"context"
 	"errors"
-	"image"
-	"image/color"
+	"fmt"
 	"image/png"
-	"os"
+	"io"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type State int
@@ -17,252 +20,606 @@ const (
 	Blank
 )
 
+func (s State) String() string {
+	switch s {
+	case Filled:
+		return "filled"
+	case Blank:
+		return "blank"
+	default:
+		return "unknown"
+	}
+}
+
 var ErrNilPattern = errors.New("called solve with nil pattern")
 var ErrContradiction = errors.New("found contradiction")
 var ErrCanNotSolve = errors.New("can not solve this puzzle completely")
 
 type Solver struct {
-	n, m    int
-	grid    [][]State
-	rows    FillPattern
-	columns FillPattern
+	// size of a rectangular puzzle, both are 0 for other geometries
+	n, m     int
+	geometry Geometry
+	lines    []Line
+	// clues[k] describes lines[k]
+	clues [][]int
+	// indexes of lines going through every cell, lines of a cell are
+	// cellLines[cellLineStart[cell]:cellLineStart[cell+1]].
+	// They are shared by all branches.
+	cellLines     []int
+	cellLineStart []int
+	grid          grid
+	// dirty[k] reports if some cells of lines[k] changed since it was
+	// solved last time. It's the queue of lines to solve: clean lines
+	// can't give new cells, so propagation skips them.
+	dirty []bool
+	// shared by the solver and all its branches
+	stats *Stats
+	// number of guesses made to get to the current grid
+	depth int
+
+	// OnStep is called on every deduction, guess and backtrack
+	// when it's not nil, see Trace
+	OnStep func(step Step)
+	// Parallel solves lines of one axis concurrently, for example all rows
+	// of a rectangular puzzle. Lines of one axis have no common cells,
+	// ValidateGeometry checks it, and their results are applied
+	// in order of indexes, so solving gives
+	// the same result and the same steps as without Parallel.
+	Parallel bool
+	// Workers limits the number of goroutines solving lines,
+	// runtime.GOMAXPROCS(0) is used when Workers <= 0
+	Workers int
+}
+
+// Stats of the last solving, guesses made during search are included
+type Stats struct {
+	// propagation passes which deduced at least one cell, a pass solves
+	// only lines whose cells changed since they were solved last time.
+	// Passes of probe branches are not counted.
+	Passes int
+	// guessed cell states, both states of a cell count
+	Guesses int
+	// guesses which were abandoned
+	Backtracks int
+	// cell states tried by probing, both states of a cell count
+	Probes int
+	// number of times every line was solved, indexed like Geometry.Lines,
+	// so rows go before columns in rectangular puzzles
+	LineEvaluations []int
+	// number of times every line was solved while probing, indexed like
+	// LineEvaluations. Probe branches don't count in Passes, so they
+	// aren't included in LineEvaluations.
+	ProbeEvaluations []int
+}
+
+// Stats returns statistics of the last solving
+func (s *Solver) Stats() Stats {
+	if s.stats == nil {
+		return Stats{}
+	}
+
+	stats := *s.stats
+	stats.LineEvaluations = append([]int(nil), stats.LineEvaluations...)
+	stats.ProbeEvaluations = append([]int(nil), stats.ProbeEvaluations...)
+
+	return stats
+}
+
+// InterruptedError is returned by SolveContext when context is done
+// before the puzzle is solved. Solver keeps the cells deduced so far,
+// Grid is a copy of them row by row.
+type InterruptedError struct {
+	Err  error
+	Grid [][]State
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("solving interrupted: %v", e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// ContradictionError is returned when the clue of a line can't be placed
+// among cells of the line that are already known. Line holds the state
+// of the line at the moment of failure. It matches ErrContradiction
+// with errors.Is.
+type ContradictionError struct {
+	Axis  Axis
+	Index int
+	Clue  []int
+	Line  []State
+}
+
+func (e *ContradictionError) Error() string {
+	return fmt.Sprintf("%v in %s %d %v: %s", ErrContradiction, e.Axis, e.Index, e.Clue, lineString(e.Line))
+}
+
+func (e *ContradictionError) Is(target error) bool {
+	return target == ErrContradiction
 }
 
 func (s *Solver) Solve(rows FillPattern, columns FillPattern) error {
+	return s.SolveContext(context.Background(), rows, columns)
+}
+
+// SolveContext is like Solve but stops as soon as [ctx] is done.
+// Context is checked between propagation passes and on every guess.
+// Then it returns *InterruptedError wrapping ctx.Err() and solver
+// is left in a partial state, which can still be printed or saved.
+func (s *Solver) SolveContext(ctx context.Context, rows FillPattern, columns FillPattern) error {
+	if err := s.init(rows, columns); err != nil {
+		return err
+	}
+
+	return s.solveInterruptible(ctx)
+}
+
+// SolveGeometry is like SolveContext for puzzles of any shape,
+// clues[k] is the clue of the k-th line of g.Lines()
+func (s *Solver) SolveGeometry(ctx context.Context, g Geometry, clues [][]int) error {
+	if clues == nil {
+		return ErrNilPattern
+	}
+
+	if err := s.initGeometry(g, clues); err != nil {
+		return err
+	}
+
+	return s.solveInterruptible(ctx)
+}
+
+func (s *Solver) solveInterruptible(ctx context.Context) error {
+	err := s.solve(ctx)
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return &InterruptedError{Err: err, Grid: s.copyGrid()}
+	}
+
+	return err
+}
+
+// CountSolutions looks for distinct solutions of the puzzle and returns
+// at most [limit] of them, limit <= 0 means no limit.
+// Puzzle has a unique solution if exactly one solution is found with limit 2.
+// Afterwards solver holds cells deduced by propagation and probing
+// before the search. They are the same in every solution, but other
+// cells shared by all solutions may stay unknown.
+func (s *Solver) CountSolutions(rows FillPattern, columns FillPattern, limit int) ([]*Nonogram, error) {
+	return s.countSolutions(context.Background(), rows, columns, limit)
+}
+
+func (s *Solver) countSolutions(ctx context.Context, rows FillPattern, columns FillPattern, limit int) ([]*Nonogram, error) {
+	if err := s.init(rows, columns); err != nil {
+		return nil, err
+	}
+
+	err := s.propagate(ctx)
+	if err == nil {
+		err = s.probe(ctx)
+	}
+	if err != nil {
+		if errors.Is(err, ErrContradiction) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if s.isSolved() {
+		return []*Nonogram{s.ToNonogram()}, nil
+	}
+
+	var solutions []*Nonogram
+	_, err = s.search(ctx, func(solved *Solver) bool {
+		solutions = append(solutions, solved.ToNonogram())
+		return limit <= 0 || len(solutions) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return solutions, nil
+}
+
+// IsUnique reports if the puzzle has exactly one solution
+func (s *Solver) IsUnique(rows FillPattern, columns FillPattern) (bool, error) {
+	return s.isUnique(context.Background(), rows, columns)
+}
+
+func (s *Solver) isUnique(ctx context.Context, rows FillPattern, columns FillPattern) (bool, error) {
+	solutions, err := s.countSolutions(ctx, rows, columns, 2)
+	if err != nil {
+		return false, err
+	}
+
+	return len(solutions) == 1, nil
+}
+
+func (s *Solver) init(rows FillPattern, columns FillPattern) error {
 	if rows == nil || columns == nil {
 		return ErrNilPattern
 	}
 
-	s.n = len(rows)
-	s.m = len(columns)
-	s.rows = rows
-	s.columns = columns
-	s.grid = make([][]State, s.n)
-	for i := range s.n {
-		s.grid[i] = make([]State, s.m)
+	return s.initGeometry(Rect{N: len(rows), M: len(columns)}, rectClues(rows, columns))
+}
+
+func (s *Solver) initGeometry(g Geometry, clues [][]int) error {
+	if err := ValidateGeometry(g, clues); err != nil {
+		return err
+	}
+
+	s.n, s.m = 0, 0
+	if rect, ok := g.(Rect); ok {
+		s.n, s.m = rect.N, rect.M
+	}
+	s.geometry = g
+	s.lines = g.Lines()
+	s.clues = clues
+	s.stats = &Stats{
+		LineEvaluations:  make([]int, len(s.lines)),
+		ProbeEvaluations: make([]int, len(s.lines)),
+	}
+	s.grid = newGrid(g.Cells())
+
+	// lines of every cell are packed into one slice,
+	// so 1000x1000 puzzles don't need a million small slices
+	s.cellLineStart = make([]int, g.Cells()+1)
+	for _, line := range s.lines {
+		for _, cell := range line.Cells {
+			s.cellLineStart[cell+1]++
+		}
+	}
+	for cell := range g.Cells() {
+		s.cellLineStart[cell+1] += s.cellLineStart[cell]
+	}
+
+	s.cellLines = make([]int, s.cellLineStart[g.Cells()])
+	next := append([]int(nil), s.cellLineStart...)
+	s.dirty = make([]bool, len(s.lines))
+	for k, line := range s.lines {
+		for _, cell := range line.Cells {
+			s.cellLines[next[cell]] = k
+			next[cell]++
+		}
+		s.dirty[k] = true
+	}
+
+	return nil
+}
+
+func (s *Solver) solve(ctx context.Context) error {
+	if err := s.propagate(ctx); err != nil {
+		return err
 	}
 
-	return s.solve()
+	if err := s.probe(ctx); err != nil {
+		return err
+	}
+
+	if s.isSolved() {
+		return nil
+	}
+
+	var solution *Solver
+	_, err := s.search(ctx, func(solved *Solver) bool {
+		solution = solved
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	if solution == nil {
+		return ErrContradiction
+	}
+
+	s.grid = solution.grid
+	return nil
 }
 
-func (s *Solver) solve() error {
+// propagate solves dirty lines one by one
+// until no more cells can be deduced from them
+func (s *Solver) propagate(ctx context.Context) error {
 	for {
-		rowChanges, err := s.tryRows()
-		if err != nil {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		columnChanges, err := s.tryColumns()
+		changes, err := s.tryLines()
 		if err != nil {
 			return err
 		}
 
-		if rowChanges == 0 && columnChanges == 0 {
-			if s.isSolved() {
-				return nil
-			}
-
-			if !s.tryToGuess() {
-				return ErrCanNotSolve
-			} else {
-				return nil
-			}
+		if changes == 0 {
+			return nil
 		}
+		s.stats.Passes++
 	}
 }
 
-// this function tries to Fill all unknown cells from the grid
-// and check if contradiction is occured
-func (s *Solver) tryToGuess() bool {
-	for i := range s.n {
-		for j := range s.m {
-			if s.grid[i][j] != Unknown {
+// probe tries both states of every unknown cell and propagates them.
+// Cells which get the same state in both branches are set, and when one
+// branch contradicts, cells of the other branch are set. Every deduction
+// is propagated and probing goes on until it deduces nothing more.
+// Returns ErrContradiction if both states of a cell contradict.
+func (s *Solver) probe(ctx context.Context) error {
+	for progress := true; progress && !s.isSolved(); {
+		progress = false
+
+		for cell := range s.grid.size {
+			if s.grid.get(cell) != Unknown {
 				continue
 			}
 
-			newSolver := copySolver(s)
-			newSolver.grid[i][j] = Filled
-			if err := newSolver.solve(); err != nil {
+			var branches []*grid
+			for _, state := range []State{Filled, Blank} {
+				branch := copySolver(s)
+				// branches are not steps of solving, but their line
+				// evaluations are counted apart from the solving ones
+				branch.stats = &Stats{LineEvaluations: s.stats.ProbeEvaluations}
+				branch.OnStep = nil
+				branch.set(cell, state, -1)
+				s.stats.Probes++
+
+				err := branch.propagate(ctx)
+				if err != nil && !errors.Is(err, ErrContradiction) {
+					return err
+				}
+				if err == nil {
+					branches = append(branches, &branch.grid)
+				}
+			}
+
+			if len(branches) == 0 {
+				return ErrContradiction
+			}
+
+			deduced := s.grid.common(branches...)
+			if len(deduced) == 0 {
 				continue
 			}
 
-			for i1 := range s.n {
-				for j1 := range s.m {
-					s.grid[i1][j1] = newSolver.grid[i1][j1]
-				}
+			for _, i := range deduced {
+				s.set(i, branches[0].get(i), -1)
 			}
+			s.traceProbe(deduced)
 
-			return s.isSolved()
+			if err := s.propagate(ctx); err != nil {
+				return err
+			}
+			progress = true
 		}
 	}
 
-	return false
+	return nil
 }
 
-// returns count of changes done and error if contradiction is found
-func (s *Solver) tryRows() (int, error) {
-	changesCount := 0
+// search is a depth first search over unknown cells.
+// It guesses the state of a cell, propagates the guess and goes deeper
+// until the puzzle is solved or a contradiction is found.
+// Then it tries the opposite state of the cell. It calls [found] on
+// every solution and stops as soon as [found] returns false.
+// Returns true if the search was stopped.
+func (s *Solver) search(ctx context.Context, found func(solved *Solver) bool) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	cell := s.chooseCell()
 
-	row := make([]State, s.n)
-	for rowIdx := range s.n {
-		var v variant
+	for _, state := range []State{Filled, Blank} {
+		branch := copySolver(s)
+		branch.depth = s.depth + 1
+		branch.set(cell, state, -1)
+		s.stats.Guesses++
+		s.traceGuess(cell, state, branch.depth)
 
-		rowFills := make([]int, s.n)
-		varCount := 0
-		for curVar := range v.Provide(s.n, s.rows[rowIdx]) {
-			fillWith(row, curVar, s.rows[rowIdx])
+		err := branch.propagate(ctx)
+		if err != nil && !errors.Is(err, ErrContradiction) {
+			return false, err
+		}
 
-			isSuitable := true
-			for column := range s.m {
-				if !isPossible(s.grid[rowIdx][column], row[column]) {
-					isSuitable = false
-					break
+		if err == nil {
+			if branch.isSolved() {
+				if !found(branch) {
+					return true, nil
+				}
+			} else {
+				stopped, err := branch.search(ctx, found)
+				if err != nil || stopped {
+					return stopped, err
 				}
 			}
+		}
 
-			if !isSuitable {
-				continue
-			}
+		s.stats.Backtracks++
+		s.traceBacktrack(branch)
+	}
 
-			varCount++
-			for i := range s.n {
-				if row[i] == Filled {
-					rowFills[i]++
+	return false, nil
+}
+
+// chooseCell returns the first unknown cell of the most constrained line,
+// which is the line with the least number of unknown cells.
+// Guessing there fixes the line quickly and gives propagation
+// the most information to work with.
+func (s *Solver) chooseCell() int {
+	best := -1
+	bestUnknown := s.grid.size + 1
+
+	for _, line := range s.lines {
+		unknown := 0
+		first := -1
+		for _, cell := range line.Cells {
+			if s.grid.get(cell) == Unknown {
+				unknown++
+				if first == -1 {
+					first = cell
 				}
 			}
 		}
+		if unknown > 0 && unknown < bestUnknown {
+			best, bestUnknown = first, unknown
+		}
+	}
+
+	return best
+}
 
-		if varCount == 0 {
-			return 0, ErrContradiction
+// tryLines solves dirty lines in order of their indexes, so lines which
+// become dirty later in the pass are solved in the same pass.
+// Returns count of changes done and error if contradiction is found.
+func (s *Solver) tryLines() (int, error) {
+	changesCount := 0
+
+	var dirty []int
+	for start := 0; start < len(s.lines); {
+		// lines of one axis go one after another
+		end := start + 1
+		for end < len(s.lines) && s.lines[end].Axis == s.lines[start].Axis {
+			end++
 		}
 
-		for column := range rowFills {
-			if rowFills[column] == varCount && s.grid[rowIdx][column] == Unknown {
-				s.grid[rowIdx][column] = Filled
-				changesCount++
-			} else if rowFills[column] == 0 && s.grid[rowIdx][column] == Unknown {
-				s.grid[rowIdx][column] = Blank
-				changesCount++
+		dirty = dirty[:0]
+		for k := start; k < end; k++ {
+			if s.dirty[k] {
+				s.dirty[k] = false
+				s.stats.LineEvaluations[k]++
+				dirty = append(dirty, k)
 			}
 		}
+		start = end
+
+		if s.Parallel && len(dirty) > 1 {
+			// solving a line doesn't change other lines of the axis,
+			// so all of them are solved before any result is applied
+			for i, result := range s.solveLines(dirty) {
+				changes, err := s.applyLine(dirty[i], result)
+				if err != nil {
+					return 0, err
+				}
+				changesCount += changes
+			}
+			continue
+		}
+
+		for _, k := range dirty {
+			changes, err := s.applyLine(k, s.solveLine(k))
+			if err != nil {
+				return 0, err
+			}
+			changesCount += changes
+		}
 	}
+
 	return changesCount, nil
 }
 
-// returns count of changes done and error if contradiction is found
-func (s *Solver) tryColumns() (int, error) {
-	changesCount := 0
+// lineResult is the line before and after solving
+type lineResult struct {
+	line   []State
+	solved []State
+	err    error
+}
 
-	column := make([]State, s.m)
-	for columnIdx := range s.m {
-		var v variant
+func (s *Solver) solveLine(k int) lineResult {
+	line := make([]State, len(s.lines[k].Cells))
+	for i, cell := range s.lines[k].Cells {
+		line[i] = s.grid.get(cell)
+	}
 
-		columnFills := make([]int, s.m)
-		varCount := 0
-		for curVar := range v.Provide(s.m, s.columns[columnIdx]) {
-			fillWith(column, curVar, s.columns[columnIdx])
+	solved, err := solveLine(s.clues[k], line)
+	return lineResult{line: line, solved: solved, err: err}
+}
 
-			isSuitable := true
-			for row := range s.n {
-				if !isPossible(s.grid[row][columnIdx], column[row]) {
-					isSuitable = false
-					break
-				}
-			}
+// solveLines solves lines [dirty] with a pool of Workers goroutines
+func (s *Solver) solveLines(dirty []int) []lineResult {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
 
-			if !isSuitable {
-				continue
+	results := make([]lineResult, len(dirty))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(dirty)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1)) - 1; i < len(dirty); i = int(next.Add(1)) - 1 {
+				results[i] = s.solveLine(dirty[i])
 			}
+		}()
+	}
+	wg.Wait()
 
-			varCount++
-			for i := range s.m {
-				if column[i] == Filled {
-					columnFills[i]++
-				}
-			}
-		}
+	return results
+}
 
-		if varCount == 0 {
-			return 0, ErrContradiction
+// applyLine sets cells of the line deduced by solving it
+// and returns count of changes done
+func (s *Solver) applyLine(k int, result lineResult) (int, error) {
+	line := s.lines[k]
+	if result.err != nil {
+		return 0, &ContradictionError{
+			Axis:  line.Axis,
+			Index: line.Index,
+			Clue:  s.clues[k],
+			Line:  result.line,
 		}
+	}
 
-		for row := range columnFills {
-			if columnFills[row] == varCount && s.grid[row][columnIdx] == Unknown {
-				s.grid[row][columnIdx] = Filled
-				changesCount++
-			} else if columnFills[row] == 0 && s.grid[row][columnIdx] == Unknown {
-				s.grid[row][columnIdx] = Blank
-				changesCount++
-			}
+	changesCount := 0
+	for i, cell := range line.Cells {
+		if result.line[i] == Unknown && result.solved[i] != Unknown {
+			s.set(cell, result.solved[i], k)
+			changesCount++
 		}
 	}
+
+	if s.OnStep != nil {
+		s.traceDeduction(line, s.clues[k], result.line, result.solved)
+	}
+
 	return changesCount, nil
 }
 
-// this function fills [arr] array with unbroken blocks
-// which starts in indexes stored in start
-// with length stored in [block]
-func fillWith(arr []State, start []int, block []int) {
-	for i := range arr {
-		arr[i] = Blank
-	}
-	for i := range start {
-		for j := range block[i] {
-			arr[start[i]+j] = Filled
+// set changes the state of the cell and marks lines going through it
+// as dirty, except the line [from] whose solving changed the cell
+func (s *Solver) set(cell int, state State, from int) {
+	s.grid.set(cell, state)
+	for _, k := range s.cellLines[s.cellLineStart[cell]:s.cellLineStart[cell+1]] {
+		if k != from {
+			s.dirty[k] = true
 		}
 	}
 }
 
-// [current] is a state of certain cell in grid and
-// [possible] is a state of the same cell, but we check if
-// we can set that cell to [possible] state
-func isPossible(current, possible State) bool {
-	if current == Unknown {
-		return true
-	}
+func (s *Solver) isSolved() bool {
+	return s.grid.isSolved()
+}
 
-	return current == possible
+// Cells returns a copy of cell states numbered as in the geometry
+// of the last solved puzzle
+func (s *Solver) Cells() []State {
+	return s.grid.states()
 }
 
-func (s *Solver) isSolved() bool {
-	for i := range s.n {
-		for j := range s.m {
-			if s.grid[i][j] == Unknown {
-				return false
-			}
-		}
+// cage=0 means no cage, geometries other than Rect ignore it
+func (s *Solver) toString(fill, empty, unknown rune, cage int) string {
+	f, ok := s.geometry.(formatter)
+	if !ok {
+		return ""
 	}
 
-	return true
+	return f.format(s.grid.states(), fill, empty, unknown, cage)
 }
 
-// cage=0 means no cage
-func (s *Solver) toString(fill, empty, unknown rune, cage int) string {
+// lineString shows the line the same way as Solver.String does
+func lineString(line []State) string {
 	var b strings.Builder
-
-	for i := range s.n {
-		if cage != 0 && i != 0 && i%cage == 0 {
-			for k := range s.m {
-				if k != 0 && k%cage == 0 {
-					b.WriteRune('┼')
-				}
-				b.WriteRune('─')
-			}
-			b.WriteRune('\n')
-		}
-		for j := range s.m {
-			if cage != 0 && j != 0 && j%cage == 0 {
-				b.WriteRune('│')
-			}
-			if s.grid[i][j] == Filled {
-				b.WriteRune(fill)
-			} else if s.grid[i][j] == Blank {
-				b.WriteRune(empty)
-			} else if s.grid[i][j] == Unknown {
-				b.WriteRune(unknown)
-			}
-		}
-		b.WriteRune('\n')
+	for _, state := range line {
+		b.WriteRune(stateRune(state, '#', 'x', '.'))
 	}
 
 	return b.String()
@@ -284,48 +641,61 @@ func (s *Solver) PrettyStringCaged(cage int) string {
 	return s.toString('█', '╳', ' ', cage)
 }
 
-// When solver saves png, it paints cells in black if it's Filled,
-// in white if it's Blank and in red if it's Unknown
-func (s *Solver) SavePNG(name string, scale int) error {
-	img := image.NewRGBA(image.Rect(0, 0, s.m*scale, s.n*scale))
-
-	for i := 0; i < s.n*scale; i++ {
-		for j := 0; j < s.m*scale; j++ {
-			var c color.RGBA
-			if s.grid[i/scale][j/scale] == Filled {
-				c = color.RGBA{0, 0, 0, 255}
-			} else if s.grid[i/scale][j/scale] == Blank {
-				c = color.RGBA{255, 255, 255, 255}
-			} else {
-				c = color.RGBA{255, 0, 0, 255}
-			}
-			img.Set(j, i, c)
-		}
-	}
+// StringWithClues shows the grid with clues along the top and the left
+// like in the FillPattern diagram, cage=0 means no cage.
+// It's empty for puzzles that are not rectangular.
+func (s *Solver) StringWithClues(cage int) string {
+	return s.cluedString('#', 'x', '.', cage)
+}
 
-	f, err := os.Create(name)
-	if err != nil {
-		return err
+func (s *Solver) PrettyStringWithClues(cage int) string {
+	return s.cluedString('█', '╳', ' ', cage)
+}
+
+func (s *Solver) cluedString(fill, empty, unknown rune, cage int) string {
+	if s.n == 0 || s.m == 0 {
+		return ""
 	}
 
-	if err := png.Encode(f, img); err != nil {
-		f.Close()
-		return err
+	return cluedString(s.clues[:s.n], s.clues[s.n:], func(i, j int) rune {
+		return stateRune(s.get(i, j), fill, empty, unknown)
+	}, cage)
+}
+
+// When solver saves png, it paints cells in black if it's Filled,
+// in white if it's Blank and in red if it's Unknown.
+// Returns ErrNotRectangular for puzzles of other geometries.
+func (s *Solver) SavePNG(name string, scale int) error {
+	if s.n == 0 || s.m == 0 {
+		return ErrNotRectangular
 	}
 
-	if err := f.Close(); err != nil {
-		return err
+	return savePNG(name, func(w io.Writer) error {
+		return s.EncodePNG(w, PNGOptions{Scale: scale})
+	})
+}
+
+// EncodePNG writes the grid to [w] as png image drawn with [opts].
+// Returns ErrNotRectangular for puzzles of other geometries.
+func (s *Solver) EncodePNG(w io.Writer, opts PNGOptions) error {
+	if s.n == 0 || s.m == 0 {
+		return ErrNotRectangular
 	}
 
-	return nil
+	return png.Encode(w, drawPNG(s.n, s.m, s.get, opts))
 }
 
+// ToNonogram returns nil for puzzles that are not rectangular
 func (s *Solver) ToNonogram() *Nonogram {
+	if s.n == 0 || s.m == 0 {
+		return nil
+	}
+
 	nono := New(s.n, s.m)
 
 	for i := 0; i < s.n; i++ {
 		for j := 0; j < s.m; j++ {
-			if s.grid[i][j] == Filled {
+			if s.get(i, j) == Filled {
 				nono.Fill(i, j)
 			}
 		}
@@ -334,23 +704,45 @@ func (s *Solver) ToNonogram() *Nonogram {
 	return nono
 }
 
-func copySolver(s *Solver) *Solver {
-	newSolver := Solver{
-		n:       s.n,
-		m:       s.m,
-		rows:    s.rows,
-		columns: s.columns,
-		grid:    make([][]State, s.n),
-	}
+// get returns state of the cell of a rectangular puzzle
+func (s *Solver) get(i, j int) State {
+	return s.grid.get(i*s.m + j)
+}
 
-	for i := range s.n {
-		newSolver.grid[i] = make([]State, s.m)
-	}
+// copyGrid returns cells of every row
+func (s *Solver) copyGrid() [][]State {
+	var grid [][]State
+	for _, line := range s.lines {
+		if line.Axis != Row {
+			continue
+		}
 
-	for i := range s.n {
-		for j := range s.m {
-			newSolver.grid[i][j] = s.grid[i][j]
+		row := make([]State, 0, len(line.Cells))
+		for _, cell := range line.Cells {
+			row = append(row, s.grid.get(cell))
 		}
+		grid = append(grid, row)
+	}
+
+	return grid
+}
+
+func copySolver(s *Solver) *Solver {
+	newSolver := Solver{
+		n:             s.n,
+		m:             s.m,
+		geometry:      s.geometry,
+		lines:         s.lines,
+		clues:         s.clues,
+		cellLines:     s.cellLines,
+		cellLineStart: s.cellLineStart,
+		grid:          s.grid.clone(),
+		dirty:         append([]bool(nil), s.dirty...),
+		stats:         s.stats,
+		depth:         s.depth,
+		OnStep:        s.OnStep,
+		Parallel:      s.Parallel,
+		Workers:       s.Workers,
 	}
 
 	return &newSolver
@@ -0,0 +1,534 @@
+package nonogram_test
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     nonogram.FillPattern
+		columns  nonogram.FillPattern
+		expected string
+	}{
+		{
+			name:    "small size",
+			rows:    nonogram.FillPattern{{1}, {1, 1}, {1}},
+			columns: nonogram.FillPattern{{1}, {1, 1}, {1}},
+			expected: `x#x
+#x#
+x#x
+`,
+		},
+		{
+			name:    "empty lines",
+			rows:    nonogram.FillPattern{{0}, {3}, {0}},
+			columns: nonogram.FillPattern{{1}, {1}, {1}},
+			expected: `xxx
+###
+xxx
+`,
+		},
+		{
+			name:    "middle size",
+			rows:    nonogram.FillPattern{{3}, {1, 1}, {5}, {1, 1}, {3}},
+			columns: nonogram.FillPattern{{1}, {5}, {1, 1, 1}, {5}, {1}},
+			expected: `x###x
+x#x#x
+#####
+x#x#x
+x###x
+`,
+		},
+		{
+			name:    "wide size",
+			rows:    nonogram.FillPattern{{2, 1}, {5}},
+			columns: nonogram.FillPattern{{2}, {2}, {1}, {2}, {1}},
+			expected: `##x#x
+#####
+`,
+		},
+		{
+			name:    "tall size",
+			rows:    nonogram.FillPattern{{1}, {2}, {1}, {0}, {2}},
+			columns: nonogram.FillPattern{{3, 1}, {1, 1}},
+			expected: `#x
+##
+#x
+xx
+##
+`,
+		},
+		{
+			name:    "needs search",
+			rows:    nonogram.FillPattern{{1, 1}, {1, 1}, {1, 1}, {1, 1}},
+			columns: nonogram.FillPattern{{2}, {2}, {2}, {2}},
+			expected: `#x#x
+#x#x
+x#x#
+x#x#
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s nonogram.Solver
+
+			require.NoError(t, s.Solve(tt.rows, tt.columns))
+			require.Equal(t, tt.expected, s.String())
+		})
+	}
+}
+
+func TestSolveBig(t *testing.T) {
+	rows := nonogram.FillPattern{
+		{3, 3}, {5, 7, 5}, {2, 12, 2}, {2, 12, 2}, {18},
+		{17}, {18}, {4, 6, 6}, {4, 6, 6}, {3, 1, 2, 5},
+		{5, 3, 7}, {4, 3, 6}, {4, 1, 1, 1, 5}, {3, 5, 5}, {3, 7},
+		{2, 2, 5}, {10, 6}, {4, 6}, {4, 7}, {4, 6},
+		{4, 7}, {7, 3, 3, 2}, {9, 6, 2, 2}, {2, 9, 6, 3, 3}, {1, 7, 14},
+		{1, 7, 13}, {2, 8, 10}, {11, 10}, {6, 13}, {10},
+	}
+	columns := nonogram.FillPattern{
+		{4}, {2, 2}, {2, 2}, {3, 3}, {8},
+		{3, 6, 10}, {5, 8, 11}, {2, 11, 12}, {2, 5, 5, 12}, {6, 1, 5, 6},
+		{5, 1, 1, 3, 6}, {7, 1, 1, 2, 3}, {8, 2, 1, 1, 2, 2}, {8, 4, 1, 2, 2}, {8, 2, 1, 1, 3, 3},
+		{8, 1, 1, 8}, {9, 1, 1, 8}, {6, 1, 2, 7}, {6, 1, 2, 7}, {7, 5, 7},
+		{14, 6}, {2, 13, 5}, {2, 15, 6}, {28}, {3, 5, 13},
+		{7, 2}, {5, 2}, {4, 3}, {7}, {4},
+	}
+
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(rows, columns))
+
+	solvedRows, solvedColumns := s.ToNonogram().FillPatterns()
+	require.Equal(t, rows, solvedRows)
+	require.Equal(t, columns, solvedColumns)
+}
+
+func TestSolveWordBoundary(t *testing.T) {
+	// cells are packed 64 to a word, so the last cells of these grids
+	// are around the boundary of a word
+	for _, m := range []int{63, 64, 65, 128, 129} {
+		t.Run(strconv.Itoa(m), func(t *testing.T) {
+			columns := make(nonogram.FillPattern, m)
+			for j := range columns {
+				columns[j] = []int{1}
+			}
+			columns[m-1] = []int{2}
+
+			var s nonogram.Solver
+			require.NoError(t, s.Solve(nonogram.FillPattern{{1}, {m}}, columns))
+			require.Equal(t, strings.Repeat("x", m-1)+"#\n"+strings.Repeat("#", m)+"\n", s.String())
+			require.Zero(t, s.Stats().Guesses)
+
+			// two solutions differ only in the last two columns, so cells
+			// of both branches stay apart and the solver leaves them unknown
+			for j := range columns {
+				columns[j] = []int{0}
+			}
+			columns[m-2], columns[m-1] = []int{1}, []int{1}
+
+			solutions, err := s.CountSolutions(nonogram.FillPattern{{1}, {1}}, columns, 0)
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{
+				strings.Repeat(".", m-2) + "#.\n" + strings.Repeat(".", m-1) + "#\n",
+				strings.Repeat(".", m-1) + "#\n" + strings.Repeat(".", m-2) + "#.\n",
+			}, []string{solutions[0].String(), solutions[1].String()})
+			empty := strings.Repeat("x", m-2)
+			require.Equal(t, empty+"..\n"+empty+"..\n", s.String())
+		})
+	}
+}
+
+func TestSolveLineEvaluations(t *testing.T) {
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(nonogram.FillPattern{{1}, {0}}, nonogram.FillPattern{{1}, {0}}))
+	// only the first row gets cells from columns after it was solved,
+	// so it's the only line checked again in the pass that deduces nothing
+	require.Equal(t, 1, s.Stats().Passes)
+	require.Equal(t, []int{2, 1, 1, 1}, s.Stats().LineEvaluations)
+
+	gram := nonogram.GenRand(rand.New(rand.NewSource(7)), 30, 30)
+	rows, columns := gram.FillPatterns()
+	probes := 0
+	s.OnStep = func(step nonogram.Step) {
+		if step.Kind == nonogram.Probe {
+			probes++
+		}
+	}
+	require.NoError(t, s.Solve(rows, columns))
+
+	stats := s.Stats()
+	require.Positive(t, probes)
+	total := 0
+	for _, evaluations := range stats.LineEvaluations {
+		require.Positive(t, evaluations)
+		total += evaluations
+	}
+	probed := 0
+	for _, evaluations := range stats.ProbeEvaluations {
+		probed += evaluations
+	}
+	require.Positive(t, probed)
+	// every pass solves only lines that changed, and propagation runs
+	// one pass which deduces nothing at the start, after every probe
+	// which deduced cells and after every guess
+	require.LessOrEqual(t, total, (stats.Passes+1+probes+stats.Guesses)*(len(rows)+len(columns)))
+}
+
+func TestSolveProbing(t *testing.T) {
+	// the line solver gets stuck after two cells, but leaving the top left
+	// cell blank contradicts, which is enough to solve the puzzle without guesses
+	rows := nonogram.FillPattern{{1, 1}, {1}, {3}, {2}}
+	columns := nonogram.FillPattern{{2}, {1}, {2}, {2}, {1}}
+
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(rows, columns))
+	require.Equal(t, "#xxx#\n#xxxx\nx###x\nxx##x\n", s.String())
+	require.Zero(t, s.Stats().Guesses)
+	require.Positive(t, s.Stats().Probes)
+}
+
+func TestSolverStringWithClues(t *testing.T) {
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(nonogram.FillPattern{{1}, {2}}, nonogram.FillPattern{{1}, {2}}))
+
+	require.Equal(t, ""+
+		"   12\n"+
+		"  ┌──┐\n"+
+		" 1│x#│\n"+
+		" 2│##│\n"+
+		"  └──┘\n", s.StringWithClues(0))
+	require.Equal(t, ""+
+		"   1 2\n"+
+		"  ┌─┬─┐\n"+
+		" 1│╳│█│\n"+
+		"  ├─┼─┤\n"+
+		" 2│█│█│\n"+
+		"  └─┴─┘\n", s.PrettyStringWithClues(1))
+}
+
+func TestSolveContradiction(t *testing.T) {
+	var s nonogram.Solver
+
+	err := s.Solve(nonogram.FillPattern{{1, 1}, {0}}, nonogram.FillPattern{{1}, {1}, {0}})
+	require.ErrorIs(t, err, nonogram.ErrContradiction)
+}
+
+func TestSolveRandom(t *testing.T) {
+	sizes := []struct {
+		n, m int
+	}{
+		{12, 12},
+		{1, 15},
+		{15, 1},
+		{4, 16},
+		{16, 4},
+		{7, 13},
+		{13, 7},
+	}
+
+	for _, size := range sizes {
+		for range 20 {
+			gram := nonogram.Gen(size.n, size.m)
+			rows, columns := gram.FillPatterns()
+
+			var s nonogram.Solver
+			require.NoError(t, s.Solve(rows, columns))
+
+			solvedRows, solvedColumns := s.ToNonogram().FillPatterns()
+			require.Equal(t, rows, solvedRows)
+			require.Equal(t, columns, solvedColumns)
+		}
+	}
+}
+
+func TestSolveParallel(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for _, workers := range []int{0, 1, 3} {
+		for range 20 {
+			gram := nonogram.GenRand(r, 12, 15)
+			rows, columns := gram.FillPatterns()
+
+			var sequentialTrace, parallelTrace nonogram.Trace
+			sequential := nonogram.Solver{OnStep: sequentialTrace.Record}
+			parallel := nonogram.Solver{OnStep: parallelTrace.Record, Parallel: true, Workers: workers}
+
+			require.NoError(t, sequential.Solve(rows, columns))
+			require.NoError(t, parallel.Solve(rows, columns))
+			require.Equal(t, sequential.String(), parallel.String())
+			require.Equal(t, sequential.Stats(), parallel.Stats())
+			require.Equal(t, sequentialTrace, parallelTrace)
+		}
+	}
+
+	// contradiction is reported in the first line of the axis
+	rows := nonogram.FillPattern{{3}, {0}, {1}}
+	columns := nonogram.FillPattern{{1}, {2}, {1}}
+	s := nonogram.Solver{Parallel: true}
+	var contradiction *nonogram.ContradictionError
+	require.ErrorAs(t, s.Solve(rows, columns), &contradiction)
+	require.Equal(t, nonogram.Column, contradiction.Axis)
+	require.Equal(t, 1, contradiction.Index)
+}
+
+func BenchmarkSolve100(b *testing.B) {
+	g := nonogram.Generator{Seed: 1, Density: 0.6}
+	gram, err := g.Generate(100, 100)
+	require.NoError(b, err)
+	rows, columns := gram.FillPatterns()
+
+	for _, bench := range []struct {
+		name     string
+		parallel bool
+	}{
+		{name: "sequential"},
+		{name: "parallel", parallel: true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for range b.N {
+				s := nonogram.Solver{Parallel: bench.parallel}
+				require.NoError(b, s.Solve(rows, columns))
+			}
+		})
+	}
+}
+
+// the picture is a staircase which is solved in a single pass without
+// guesses, so it shows how solving lines scales with a million cells
+func BenchmarkSolve1000(b *testing.B) {
+	gram := nonogram.New(1000, 1000)
+	for i := range 1000 {
+		for j := range i + 1 {
+			gram.Fill(i, j)
+		}
+	}
+	rows, columns := gram.FillPatterns()
+
+	b.ReportAllocs()
+	for range b.N {
+		var s nonogram.Solver
+		require.NoError(b, s.Solve(rows, columns))
+	}
+}
+
+func TestSavePNGRectangular(t *testing.T) {
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(nonogram.FillPattern{{1}, {0}}, nonogram.FillPattern{{0}, {0}, {1}}))
+
+	name := filepath.Join(t.TempDir(), "solved.png")
+	require.NoError(t, s.SavePNG(name, 2))
+
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	require.Equal(t, 6, img.Bounds().Dx())
+	require.Equal(t, 4, img.Bounds().Dy())
+
+	black := color.RGBAModel.Convert(color.Black)
+	white := color.RGBAModel.Convert(color.White)
+	require.Equal(t, black, color.RGBAModel.Convert(img.At(5, 1)))
+	require.Equal(t, white, color.RGBAModel.Convert(img.At(0, 0)))
+	require.Equal(t, white, color.RGBAModel.Convert(img.At(5, 2)))
+}
+
+func TestCountSolutions(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     nonogram.FillPattern
+		columns  nonogram.FillPattern
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "unique",
+			rows:     nonogram.FillPattern{{1}, {1, 1}, {1}},
+			columns:  nonogram.FillPattern{{1}, {1, 1}, {1}},
+			limit:    2,
+			expected: []string{".#.\n#.#\n.#.\n"},
+		},
+		{
+			name:     "two solutions",
+			rows:     nonogram.FillPattern{{1}, {1}},
+			columns:  nonogram.FillPattern{{1}, {1}},
+			limit:    2,
+			expected: []string{"#.\n.#\n", ".#\n#.\n"},
+		},
+		{
+			name:     "stop at limit",
+			rows:     nonogram.FillPattern{{1}, {1}, {1}},
+			columns:  nonogram.FillPattern{{1}, {1}, {1}},
+			limit:    2,
+			expected: []string{"#..\n.#.\n..#\n", "#..\n..#\n.#.\n"},
+		},
+		{
+			name:    "no limit",
+			rows:    nonogram.FillPattern{{1}, {1}, {1}},
+			columns: nonogram.FillPattern{{1}, {1}, {1}},
+			limit:   0,
+			expected: []string{
+				"#..\n.#.\n..#\n", "#..\n..#\n.#.\n",
+				".#.\n#..\n..#\n", ".#.\n..#\n#..\n",
+				"..#\n#..\n.#.\n", "..#\n.#.\n#..\n",
+			},
+		},
+		{
+			name:    "no solution",
+			rows:    nonogram.FillPattern{{1, 1}, {0}},
+			columns: nonogram.FillPattern{{1}, {1}, {0}},
+			limit:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s nonogram.Solver
+			solutions, err := s.CountSolutions(tt.rows, tt.columns, tt.limit)
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(solutions))
+			for _, solution := range solutions {
+				actual = append(actual, solution.String())
+			}
+			require.ElementsMatch(t, tt.expected, actual)
+
+			unique, err := s.IsUnique(tt.rows, tt.columns)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expected) == 1, unique)
+		})
+	}
+}
+
+// countdownContext is canceled after its Err method is called [left] times
+type countdownContext struct {
+	context.Context
+	left int
+}
+
+func (c *countdownContext) Err() error {
+	if c.left <= 0 {
+		return context.Canceled
+	}
+	c.left--
+	return nil
+}
+
+func TestSolveContext(t *testing.T) {
+	rows := nonogram.FillPattern{{1, 1}, {1, 1}, {1, 1}, {1, 1}}
+	columns := nonogram.FillPattern{{2}, {2}, {2}, {2}}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{
+			name:     "before solving",
+			ctx:      &countdownContext{Context: context.Background()},
+			expected: "....\n....\n....\n....\n",
+		},
+		{
+			name:     "during probing",
+			ctx:      &countdownContext{Context: context.Background(), left: 3},
+			expected: "....\n....\n....\n....\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s nonogram.Solver
+			err := s.SolveContext(tt.ctx, rows, columns)
+			require.ErrorIs(t, err, context.Canceled)
+
+			var interrupted *nonogram.InterruptedError
+			require.True(t, errors.As(err, &interrupted))
+			require.Len(t, interrupted.Grid, len(rows))
+			require.Equal(t, tt.expected, s.String())
+		})
+	}
+}
+
+func TestSolveContextPartial(t *testing.T) {
+	g := nonogram.Generator{Seed: 1, MinDifficulty: 3}
+	gram, err := g.Generate(15, 15)
+	require.NoError(t, err)
+
+	// the first pass succeeds and the second one is interrupted
+	ctx := &countdownContext{Context: context.Background(), left: 1}
+
+	rows, columns := gram.FillPatterns()
+
+	var s nonogram.Solver
+	err = s.SolveContext(ctx, rows, columns)
+	require.ErrorIs(t, err, context.Canceled)
+
+	var interrupted *nonogram.InterruptedError
+	require.True(t, errors.As(err, &interrupted))
+
+	known := 0
+	for i := range 15 {
+		for j := range 15 {
+			switch interrupted.Grid[i][j] {
+			case nonogram.Filled:
+				require.True(t, gram.Get(i, j))
+				known++
+			case nonogram.Blank:
+				require.False(t, gram.Get(i, j))
+				known++
+			}
+		}
+	}
+	require.Positive(t, known)
+	require.Less(t, known, 15*15)
+	require.Contains(t, s.String(), ".")
+}
+
+func TestSolveContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var s nonogram.Solver
+	err := s.SolveContext(ctx, nonogram.FillPattern{{1}}, nonogram.FillPattern{{1}})
+	require.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(t, s.SolveContext(ctx, nonogram.FillPattern{{1}}, nonogram.FillPattern{{1}}))
+	require.Equal(t, "#\n", s.String())
+}
+
+func TestSolveContradictionError(t *testing.T) {
+	var s nonogram.Solver
+
+	err := s.Solve(nonogram.FillPattern{{1, 1}, {0}}, nonogram.FillPattern{{1}, {1}, {0}})
+
+	var contradiction *nonogram.ContradictionError
+	require.True(t, errors.As(err, &contradiction))
+	require.Equal(t, &nonogram.ContradictionError{
+		Axis:  nonogram.Column,
+		Index: 1,
+		Clue:  []int{1},
+		Line:  []nonogram.State{nonogram.Blank, nonogram.Blank},
+	}, contradiction)
+	require.EqualError(t, err, "found contradiction in column 1 [1]: xx")
+	require.Equal(t, "#x#\nxxx\n", s.String())
+}
@@ -0,0 +1,181 @@
+package nonogram
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const defaultSVGScale = 20
+
+// SVGOptions tell how to draw svg images. Zero value draws
+// the grid with 20 pixels wide cells, without cage and clues.
+type SVGOptions struct {
+	// width of a cell in pixels, 20 is used when Scale <= 0
+	Scale int
+	// number of cells between thick lines like in StringCaged,
+	// 0 means no cage
+	Cage int
+	// draw clues along the top and the left of the grid
+	Clues bool
+}
+
+// svgGrid is a grid drawn as svg image. Every group of elements has
+// a class, so the style can be changed with css when svg is embedded
+// into html: filled, blank, unknown, grid, cage and clue.
+type svgGrid struct {
+	n, m int
+	// clues are drawn when they are not nil
+	rows, columns FillPattern
+	// cells are not drawn when it's nil
+	cell func(i, j int) State
+	// blank cells are crossed out, otherwise they are left empty
+	crossBlanks bool
+	scale, cage int
+}
+
+// WriteSVG draws the grid as svg image. Filled cells are black,
+// blank cells are crossed out and unknown cells are gray.
+// Returns ErrNotRectangular for puzzles of other geometries.
+func (s *Solver) WriteSVG(w io.Writer, opts SVGOptions) error {
+	if s.n == 0 || s.m == 0 {
+		return ErrNotRectangular
+	}
+
+	g := svgGrid{
+		n:           s.n,
+		m:           s.m,
+		cell:        s.get,
+		crossBlanks: true,
+		scale:       opts.Scale,
+		cage:        opts.Cage,
+	}
+	if opts.Clues {
+		g.rows, g.columns = s.clues[:s.n], s.clues[s.n:]
+	}
+
+	return g.write(w)
+}
+
+// WriteSVG draws the grid as svg image with black filled cells
+func (n *Nonogram) WriteSVG(w io.Writer, opts SVGOptions) error {
+	g := svgGrid{
+		n: n.n,
+		m: n.m,
+		cell: func(i, j int) State {
+			if n.Get(i, j) {
+				return Filled
+			}
+			return Blank
+		},
+		scale: opts.Scale,
+		cage:  opts.Cage,
+	}
+	if opts.Clues {
+		g.rows, g.columns = n.FillPatterns()
+	}
+
+	return g.write(w)
+}
+
+func (g svgGrid) write(w io.Writer) error {
+	scale := g.scale
+	if scale <= 0 {
+		scale = defaultSVGScale
+	}
+
+	left, top := 0, 0
+	for _, clue := range g.rows {
+		left = max(left, len(clue))
+	}
+	for _, clue := range g.columns {
+		top = max(top, len(clue))
+	}
+
+	// every coordinate is shifted by 1 pixel, so thick border is not cut
+	x := func(j int) int { return (left+j)*scale + 1 }
+	y := func(i int) int { return (top+i)*scale + 1 }
+	width, height := x(g.m)+1, y(g.n)+1
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	b.WriteString(`<rect width="100%" height="100%" fill="white"/>` + "\n")
+
+	if g.cell != nil {
+		for _, style := range []struct {
+			state State
+			fill  string
+		}{
+			{Filled, "black"},
+			{Unknown, "#d8d8d8"},
+		} {
+			fmt.Fprintf(&b, `<g class="%s" fill="%s">`+"\n", style.state, style.fill)
+			for i := range g.n {
+				for j := range g.m {
+					if g.cell(i, j) == style.state {
+						fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d"/>`+"\n", x(j), y(i), scale, scale)
+					}
+				}
+			}
+			b.WriteString("</g>\n")
+		}
+
+		if g.crossBlanks {
+			inset := scale / 4
+			b.WriteString(`<g class="blank" stroke="#808080" stroke-width="1">` + "\n")
+			for i := range g.n {
+				for j := range g.m {
+					if g.cell(i, j) == Blank {
+						x1, y1, x2, y2 := x(j)+inset, y(i)+inset, x(j+1)-inset, y(i+1)-inset
+						fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x1, y1, x2, y2)
+						fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x1, y2, x2, y1)
+					}
+				}
+			}
+			b.WriteString("</g>\n")
+		}
+	}
+
+	if g.rows != nil || g.columns != nil {
+		fmt.Fprintf(&b, `<g class="clue" font-family="sans-serif" font-size="%.1f" text-anchor="middle" dominant-baseline="central">`+"\n", float64(scale)*0.6)
+		text := func(number, column, row int) {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%d</text>`+"\n", float64(column*scale)+float64(scale)/2+1, float64(row*scale)+float64(scale)/2+1, number)
+		}
+		for i, clue := range g.rows {
+			for k, size := range clue {
+				text(size, left-len(clue)+k, top+i)
+			}
+		}
+		for j, clue := range g.columns {
+			for k, size := range clue {
+				text(size, left+j, top-len(clue)+k)
+			}
+		}
+		b.WriteString("</g>\n")
+	}
+
+	// thick lines go over thin ones
+	for _, thick := range []bool{false, true} {
+		if thick {
+			b.WriteString(`<g class="cage" stroke="black" stroke-width="2">` + "\n")
+		} else {
+			b.WriteString(`<g class="grid" stroke="#c8c8c8" stroke-width="1">` + "\n")
+		}
+
+		for i := range g.n + 1 {
+			if thick == (i == 0 || i == g.n || g.cage != 0 && i%g.cage == 0) {
+				fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x(0), y(i), x(g.m), y(i))
+			}
+		}
+		for j := range g.m + 1 {
+			if thick == (j == 0 || j == g.m || g.cage != 0 && j%g.cage == 0) {
+				fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x(j), y(0), x(j), y(g.n))
+			}
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,92 @@
+package nonogram_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+// svgElements counts elements of every group class of svg image
+func svgElements(t *testing.T, svg string) map[string]int {
+	var image struct {
+		Width  int `xml:"width,attr"`
+		Groups []struct {
+			Class string     `xml:"class,attr"`
+			Rects []struct{} `xml:"rect"`
+			Lines []struct{} `xml:"line"`
+			Texts []string   `xml:"text"`
+		} `xml:"g"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(svg), &image))
+
+	counts := make(map[string]int)
+	for _, g := range image.Groups {
+		counts[g.Class] += len(g.Rects) + len(g.Lines) + len(g.Texts)
+	}
+
+	return counts
+}
+
+func TestSolverWriteSVG(t *testing.T) {
+	rows := nonogram.FillPattern{{3}, {1, 1}, {5}, {1, 1}, {3}}
+	columns := nonogram.FillPattern{{1}, {5}, {1, 1, 1}, {5}, {1}}
+
+	var s nonogram.Solver
+	require.NoError(t, s.Solve(rows, columns))
+
+	tests := []struct {
+		name     string
+		opts     nonogram.SVGOptions
+		prefix   string
+		expected map[string]int
+	}{
+		{
+			name:   "default",
+			prefix: `<svg xmlns="http://www.w3.org/2000/svg" width="102" height="102"`,
+			// 15 filled cells, 10 blank cells crossed with 2 lines,
+			// 4 inner lines of every axis and the border
+			expected: map[string]int{"filled": 15, "unknown": 0, "blank": 20, "grid": 8, "cage": 4},
+		},
+		{
+			name:     "caged with clues",
+			opts:     nonogram.SVGOptions{Scale: 10, Cage: 2, Clues: true},
+			prefix:   `<svg xmlns="http://www.w3.org/2000/svg" width="72" height="82"`,
+			expected: map[string]int{"filled": 15, "unknown": 0, "blank": 20, "grid": 4, "cage": 8, "clue": 14},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			require.NoError(t, s.WriteSVG(&b, tt.opts))
+			require.True(t, strings.HasPrefix(b.String(), tt.prefix))
+			require.Equal(t, tt.expected, svgElements(t, b.String()))
+		})
+	}
+}
+
+func TestSolverWriteSVGUnknown(t *testing.T) {
+	var s nonogram.Solver
+	solutions, err := s.CountSolutions(nonogram.FillPattern{{1}, {1}}, nonogram.FillPattern{{1}, {1}}, 0)
+	require.NoError(t, err)
+	require.Len(t, solutions, 2)
+
+	var b strings.Builder
+	require.NoError(t, s.WriteSVG(&b, nonogram.SVGOptions{}))
+	require.Equal(t, map[string]int{"filled": 0, "unknown": 4, "blank": 0, "grid": 2, "cage": 4}, svgElements(t, b.String()))
+}
+
+func TestNonogramWriteSVG(t *testing.T) {
+	gram := nonogram.New(2, 3)
+	gram.Fill(0, 0)
+	gram.Fill(1, 2)
+
+	var b strings.Builder
+	require.NoError(t, gram.WriteSVG(&b, nonogram.SVGOptions{Clues: true}))
+	require.Contains(t, b.String(), `<rect x="21" y="21" width="20" height="20"/>`)
+	require.Equal(t, map[string]int{"filled": 2, "unknown": 0, "grid": 3, "cage": 4, "clue": 5}, svgElements(t, b.String()))
+}
@@ -0,0 +1,203 @@
+package nonogram
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepKind tells what solver did on a step
+type StepKind int
+
+const (
+	// cells of a line were deduced from its clue
+	Deduction StepKind = iota + 1
+	// state of a cell was guessed
+	Guess
+	// guess was abandoned and cells deduced after it became unknown again
+	Backtrack
+	// cells were deduced by trying both states of a cell,
+	// they have the same state in both branches or one branch contradicts
+	Probe
+)
+
+func (k StepKind) String() string {
+	switch k {
+	case Deduction:
+		return "deduction"
+	case Guess:
+		return "guess"
+	case Backtrack:
+		return "backtrack"
+	case Probe:
+		return "probe"
+	default:
+		return "unknown step"
+	}
+}
+
+// Rule used to deduce cells of a line
+type Rule int
+
+const (
+	// cells covered by a block both in the leftmost and in the rightmost
+	// placement of the clue, and cells that no block can reach
+	RuleOverlap Rule = iota + 1
+	// cells that have the same state in every placement of the clue,
+	// which covers gaps between known cells and edges of the line
+	RuleIntersection
+)
+
+func (r Rule) String() string {
+	switch r {
+	case RuleOverlap:
+		return "overlap"
+	case RuleIntersection:
+		return "intersection"
+	default:
+		return "unknown rule"
+	}
+}
+
+// Change of a cell state
+type Change struct {
+	Row, Column int
+	State       State
+}
+
+// Step of solving. Axis, Index and Rule are set only for deductions.
+// Depth is the number of guesses made before the step.
+type Step struct {
+	Kind    StepKind
+	Axis    Axis
+	Index   int
+	Rule    Rule
+	Depth   int
+	Changes []Change
+}
+
+func (s Step) String() string {
+	var b strings.Builder
+
+	switch s.Kind {
+	case Deduction:
+		fmt.Fprintf(&b, "%s %d by %s:", s.Axis, s.Index, s.Rule)
+		for _, c := range s.Changes {
+			fmt.Fprintf(&b, " (%d, %d) %s", c.Row, c.Column, c.State)
+		}
+	case Guess:
+		c := s.Changes[0]
+		fmt.Fprintf(&b, "guess (%d, %d) is %s", c.Row, c.Column, c.State)
+	case Backtrack:
+		fmt.Fprintf(&b, "backtrack, %d cells are unknown again", len(s.Changes))
+	case Probe:
+		b.WriteString("probe:")
+		for _, c := range s.Changes {
+			fmt.Fprintf(&b, " (%d, %d) %s", c.Row, c.Column, c.State)
+		}
+	}
+	fmt.Fprintf(&b, " at depth %d", s.Depth)
+
+	return b.String()
+}
+
+// Trace records steps of solving. Pass Trace.Record to Solver.OnStep:
+//
+//	var trace nonogram.Trace
+//	s := nonogram.Solver{OnStep: trace.Record}
+type Trace []Step
+
+func (t *Trace) Record(step Step) {
+	*t = append(*t, step)
+}
+
+// Replay applies the first [steps] steps of the trace to an empty grid
+// of the puzzle and returns solver holding the result
+func (t Trace) Replay(rows FillPattern, columns FillPattern, steps int) (*Solver, error) {
+	var s Solver
+	if err := s.init(rows, columns); err != nil {
+		return nil, err
+	}
+
+	for _, step := range t[:min(steps, len(t))] {
+		for _, c := range step.Changes {
+			s.grid.set(c.Row*s.m+c.Column, c.State)
+		}
+	}
+
+	return &s, nil
+}
+
+// traceDeduction reports cells of the line which were deduced by the line
+// solver, [line] is the line before solving and [solved] is after it
+func (s *Solver) traceDeduction(l Line, clue []int, line, solved []State) {
+	overlap, err := overlapLine(clue, line)
+	if err != nil {
+		return
+	}
+
+	var byOverlap, byIntersection []Change
+	for k := range line {
+		if line[k] != Unknown || solved[k] == Unknown {
+			continue
+		}
+
+		c := Change{State: solved[k]}
+		c.Row, c.Column = s.geometry.Position(l.Cells[k])
+
+		if overlap[k] == solved[k] {
+			byOverlap = append(byOverlap, c)
+		} else {
+			byIntersection = append(byIntersection, c)
+		}
+	}
+
+	if len(byOverlap) > 0 {
+		s.OnStep(Step{Kind: Deduction, Axis: l.Axis, Index: l.Index, Rule: RuleOverlap, Depth: s.depth, Changes: byOverlap})
+	}
+	if len(byIntersection) > 0 {
+		s.OnStep(Step{Kind: Deduction, Axis: l.Axis, Index: l.Index, Rule: RuleIntersection, Depth: s.depth, Changes: byIntersection})
+	}
+}
+
+// traceProbe reports [cells] deduced by probing
+func (s *Solver) traceProbe(cells []int) {
+	if s.OnStep == nil {
+		return
+	}
+
+	changes := make([]Change, 0, len(cells))
+	for _, cell := range cells {
+		c := Change{State: s.grid.get(cell)}
+		c.Row, c.Column = s.geometry.Position(cell)
+		changes = append(changes, c)
+	}
+
+	s.OnStep(Step{Kind: Probe, Depth: s.depth, Changes: changes})
+}
+
+func (s *Solver) traceGuess(cell int, state State, depth int) {
+	if s.OnStep == nil {
+		return
+	}
+
+	c := Change{State: state}
+	c.Row, c.Column = s.geometry.Position(cell)
+	s.OnStep(Step{Kind: Guess, Depth: depth, Changes: []Change{c}})
+}
+
+// traceBacktrack reports cells which are known in the abandoned [branch]
+// and unknown in the solver
+func (s *Solver) traceBacktrack(branch *Solver) {
+	if s.OnStep == nil {
+		return
+	}
+
+	var changes []Change
+	for _, cell := range s.grid.common(&branch.grid) {
+		c := Change{State: Unknown}
+		c.Row, c.Column = s.geometry.Position(cell)
+		changes = append(changes, c)
+	}
+
+	s.OnStep(Step{Kind: Backtrack, Depth: branch.depth, Changes: changes})
+}
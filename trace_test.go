@@ -0,0 +1,133 @@
+package nonogram_test
+
+import (
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	rows := nonogram.FillPattern{{3}, {1, 1}, {5}, {1, 1}, {3}}
+	columns := nonogram.FillPattern{{1}, {5}, {1, 1, 1}, {5}, {1}}
+
+	var trace nonogram.Trace
+	s := nonogram.Solver{OnStep: trace.Record}
+	require.NoError(t, s.Solve(rows, columns))
+
+	require.NotEmpty(t, trace)
+	require.Equal(t, nonogram.Step{
+		Kind:  nonogram.Deduction,
+		Axis:  nonogram.Row,
+		Index: 0,
+		Rule:  nonogram.RuleOverlap,
+		Changes: []nonogram.Change{
+			{Row: 0, Column: 2, State: nonogram.Filled},
+		},
+	}, trace[0])
+	require.Equal(t, "row 0 by overlap: (0, 2) filled at depth 0", trace[0].String())
+
+	for i, step := range trace {
+		require.Equal(t, nonogram.Deduction, step.Kind)
+
+		before, err := trace.Replay(rows, columns, i)
+		require.NoError(t, err)
+		for _, c := range step.Changes {
+			require.Contains(t, before.String(), ".")
+			require.NotEqual(t, nonogram.Unknown, c.State)
+		}
+	}
+
+	replayed, err := trace.Replay(rows, columns, len(trace))
+	require.NoError(t, err)
+	require.Equal(t, s.String(), replayed.String())
+}
+
+func TestTraceRules(t *testing.T) {
+	rows := nonogram.FillPattern{{2, 2}, {1, 3}, {1, 1, 1}, {1, 1, 2}, {1, 1, 1, 1}, {3, 1}}
+	columns := nonogram.FillPattern{{3, 1}, {1}, {1, 3}, {3, 1}, {2, 3}, {0}, {2, 1}, {2}}
+
+	var trace nonogram.Trace
+	s := nonogram.Solver{OnStep: trace.Record}
+	require.NoError(t, s.Solve(rows, columns))
+
+	// after the first pass over columns the first row is "....#x.." and
+	// either block may cover the filled cell, so overlap finds nothing,
+	// but in every placement the block takes cells 3 and 4 and cell 2 is blank
+	require.Contains(t, trace, nonogram.Step{
+		Kind:  nonogram.Deduction,
+		Axis:  nonogram.Row,
+		Index: 0,
+		Rule:  nonogram.RuleIntersection,
+		Changes: []nonogram.Change{
+			{Row: 0, Column: 2, State: nonogram.Blank},
+			{Row: 0, Column: 3, State: nonogram.Filled},
+		},
+	})
+
+	replayed, err := trace.Replay(rows, columns, len(trace))
+	require.NoError(t, err)
+	require.Equal(t, s.String(), replayed.String())
+}
+
+func TestTraceSearch(t *testing.T) {
+	rows := nonogram.FillPattern{{1}, {1}, {1}}
+	columns := nonogram.FillPattern{{1}, {1}, {1}}
+
+	var trace nonogram.Trace
+	s := nonogram.Solver{OnStep: trace.Record}
+	require.NoError(t, s.Solve(rows, columns))
+
+	kinds := make(map[nonogram.StepKind]int)
+	for _, step := range trace {
+		kinds[step.Kind]++
+	}
+	require.Positive(t, kinds[nonogram.Guess])
+	require.Equal(t, nonogram.Step{
+		Kind:    nonogram.Guess,
+		Depth:   1,
+		Changes: []nonogram.Change{{Row: 0, Column: 0, State: nonogram.Filled}},
+	}, trace[0])
+
+	replayed, err := trace.Replay(rows, columns, len(trace))
+	require.NoError(t, err)
+	require.Equal(t, s.String(), replayed.String())
+
+	trace = nil
+	solutions, err := s.CountSolutions(rows, columns, 0)
+	require.NoError(t, err)
+	require.Len(t, solutions, 6)
+
+	kinds = make(map[nonogram.StepKind]int)
+	for _, step := range trace {
+		kinds[step.Kind]++
+	}
+	require.Equal(t, kinds[nonogram.Guess], kinds[nonogram.Backtrack])
+
+	// every guess is abandoned, so only cells common for all solutions are left
+	replayed, err = trace.Replay(rows, columns, len(trace))
+	require.NoError(t, err)
+	require.Equal(t, "...\n...\n...\n", replayed.String())
+	require.Equal(t, s.String(), replayed.String())
+}
+
+func TestTraceProbe(t *testing.T) {
+	rows := nonogram.FillPattern{{1, 1}, {1}, {3}, {2}}
+	columns := nonogram.FillPattern{{2}, {1}, {2}, {2}, {1}}
+
+	var trace nonogram.Trace
+	s := nonogram.Solver{OnStep: trace.Record}
+	require.NoError(t, s.Solve(rows, columns))
+
+	kinds := make(map[nonogram.StepKind]int)
+	for _, step := range trace {
+		kinds[step.Kind]++
+	}
+	require.Equal(t, 1, kinds[nonogram.Probe])
+	require.Zero(t, kinds[nonogram.Guess])
+
+	replayed, err := trace.Replay(rows, columns, len(trace))
+	require.NoError(t, err)
+	require.Equal(t, s.String(), replayed.String())
+}
@@ -0,0 +1,206 @@
+package nonogram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNegativeBlock = errors.New("block length is negative")
+var ErrMixedZeroBlock = errors.New("zero block is mixed with other blocks")
+var ErrLineOverflow = errors.New("blocks with gaps between them are longer than the line")
+var ErrFillMismatch = errors.New("rows and columns fill different number of cells")
+
+// Axis of a puzzle line
+type Axis int
+
+const (
+	Row Axis = iota + 1
+	Column
+	// lines of Hex from the top left to the bottom right
+	Diagonal
+	// lines of Hex from the top right to the bottom left
+	AntiDiagonal
+)
+
+func (a Axis) String() string {
+	switch a {
+	case Row:
+		return "row"
+	case Column:
+		return "column"
+	case Diagonal:
+		return "diagonal"
+	case AntiDiagonal:
+		return "anti-diagonal"
+	default:
+		return "line"
+	}
+}
+
+// ClueError describes a problem with the clue of a single line.
+// Problems of the whole puzzle have zero Axis and Index -1.
+type ClueError struct {
+	Axis   Axis
+	Index  int
+	Clue   []int
+	Reason error
+}
+
+func (e *ClueError) Error() string {
+	if e.Index < 0 {
+		return e.Reason.Error()
+	}
+
+	return fmt.Sprintf("%s %d %v: %v", e.Axis, e.Index, e.Clue, e.Reason)
+}
+
+func (e *ClueError) Unwrap() error {
+	return e.Reason
+}
+
+// ValidationError lists every problem found in clues
+type ValidationError struct {
+	Problems []*ClueError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		problems = append(problems, p.Error())
+	}
+
+	return "invalid clues: " + strings.Join(problems, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		errs = append(errs, p)
+	}
+
+	return errs
+}
+
+// Validate checks that every line of the pattern fits into [length] cells,
+// has no negative blocks and uses zero block only to describe an empty line.
+// Returns *ValidationError with all the problems found.
+func (p FillPattern) Validate(length int) error {
+	return toError(p.validate(0, length))
+}
+
+// ValidatePuzzle checks rows and columns of the puzzle with FillPattern.Validate
+// and also checks that they fill the same number of cells.
+// Returns *ValidationError with all the problems found.
+func ValidatePuzzle(rows FillPattern, columns FillPattern) error {
+	return ValidateGeometry(Rect{N: len(rows), M: len(columns)}, rectClues(rows, columns))
+}
+
+// ValidateGeometry checks that there is a clue for every line of [g],
+// lines of every axis are listed together and have no common cells,
+// every clue is valid for its line and lines of every axis fill
+// the same number of cells.
+// Returns *ValidationError with all the problems found.
+func ValidateGeometry(g Geometry, clues [][]int) error {
+	if g.Cells() <= 0 {
+		return toError([]*ClueError{{Index: -1, Reason: ErrInvalidSize}})
+	}
+
+	lines := g.Lines()
+	if len(clues) != len(lines) {
+		return toError([]*ClueError{{Index: -1, Reason: ErrClueCount}})
+	}
+
+	if problem := validateLines(g.Cells(), lines, clues); problem != nil {
+		return toError([]*ClueError{problem})
+	}
+
+	var problems []*ClueError
+	for k, line := range lines {
+		if reason := validateClue(clues[k], len(line.Cells)); reason != nil {
+			problems = append(problems, &ClueError{Axis: line.Axis, Index: line.Index, Clue: clues[k], Reason: reason})
+		}
+	}
+
+	if len(problems) > 0 {
+		return toError(problems)
+	}
+
+	filled := make(map[Axis]int)
+	for k, line := range lines {
+		filled[line.Axis] += FillPattern{clues[k]}.filled()
+	}
+	for _, count := range filled {
+		if count != filled[lines[0].Axis] {
+			return toError([]*ClueError{{Index: -1, Reason: ErrFillMismatch}})
+		}
+	}
+
+	return nil
+}
+
+// rectClues returns clues of Rect lines
+func rectClues(rows FillPattern, columns FillPattern) [][]int {
+	clues := make([][]int, 0, len(rows)+len(columns))
+	clues = append(clues, rows...)
+	return append(clues, columns...)
+}
+
+func (p FillPattern) validate(axis Axis, length int) []*ClueError {
+	var problems []*ClueError
+	for i, clue := range p {
+		if reason := validateClue(clue, length); reason != nil {
+			problems = append(problems, &ClueError{Axis: axis, Index: i, Clue: clue, Reason: reason})
+		}
+	}
+
+	return problems
+}
+
+func validateClue(clue []int, length int) error {
+	need := 0
+	zeros := 0
+	for _, size := range clue {
+		if size < 0 {
+			return ErrNegativeBlock
+		}
+		if size == 0 {
+			zeros++
+		}
+		need += size
+	}
+
+	if zeros > 0 && len(clue) > 1 {
+		return ErrMixedZeroBlock
+	}
+
+	if len(clue) > 1 {
+		need += len(clue) - 1
+	}
+
+	if need > length {
+		return ErrLineOverflow
+	}
+
+	return nil
+}
+
+// filled returns number of filled cells described by the pattern
+func (p FillPattern) filled() int {
+	res := 0
+	for _, clue := range p {
+		for _, size := range clue {
+			res += size
+		}
+	}
+
+	return res
+}
+
+func toError(problems []*ClueError) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Problems: problems}
+}
@@ -0,0 +1,131 @@
+package nonogram_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Arzeeq/nonogram"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  nonogram.FillPattern
+		length   int
+		expected []*nonogram.ClueError
+	}{
+		{
+			name:    "valid",
+			pattern: nonogram.FillPattern{{0}, {1, 2}, {5}, {}},
+			length:  5,
+		},
+		{
+			name:    "negative block",
+			pattern: nonogram.FillPattern{{1}, {2, -1}},
+			length:  5,
+			expected: []*nonogram.ClueError{
+				{Index: 1, Clue: []int{2, -1}, Reason: nonogram.ErrNegativeBlock},
+			},
+		},
+		{
+			name:    "zero mixed with blocks",
+			pattern: nonogram.FillPattern{{0, 1}, {0}, {0, 0}},
+			length:  5,
+			expected: []*nonogram.ClueError{
+				{Index: 0, Clue: []int{0, 1}, Reason: nonogram.ErrMixedZeroBlock},
+				{Index: 2, Clue: []int{0, 0}, Reason: nonogram.ErrMixedZeroBlock},
+			},
+		},
+		{
+			name:    "line overflow",
+			pattern: nonogram.FillPattern{{6}, {2, 3}, {2, 2}},
+			length:  5,
+			expected: []*nonogram.ClueError{
+				{Index: 0, Clue: []int{6}, Reason: nonogram.ErrLineOverflow},
+				{Index: 1, Clue: []int{2, 3}, Reason: nonogram.ErrLineOverflow},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pattern.Validate(tt.length)
+			if tt.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *nonogram.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			require.Equal(t, tt.expected, validationErr.Problems)
+			for _, p := range tt.expected {
+				require.ErrorIs(t, err, p.Reason)
+			}
+		})
+	}
+}
+
+func TestValidatePuzzle(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     nonogram.FillPattern
+		columns  nonogram.FillPattern
+		expected []*nonogram.ClueError
+	}{
+		{
+			name:    "valid",
+			rows:    nonogram.FillPattern{{1}, {1, 1}, {1}},
+			columns: nonogram.FillPattern{{1}, {1, 1}, {1}},
+		},
+		{
+			name:    "empty",
+			rows:    nonogram.FillPattern{},
+			columns: nonogram.FillPattern{{1}},
+			expected: []*nonogram.ClueError{
+				{Index: -1, Reason: nonogram.ErrInvalidSize},
+			},
+		},
+		{
+			name:    "both axes",
+			rows:    nonogram.FillPattern{{4}, {1}},
+			columns: nonogram.FillPattern{{1}, {1}, {-1}},
+			expected: []*nonogram.ClueError{
+				{Axis: nonogram.Row, Index: 0, Clue: []int{4}, Reason: nonogram.ErrLineOverflow},
+				{Axis: nonogram.Column, Index: 2, Clue: []int{-1}, Reason: nonogram.ErrNegativeBlock},
+			},
+		},
+		{
+			name:    "fill mismatch",
+			rows:    nonogram.FillPattern{{2}, {0}},
+			columns: nonogram.FillPattern{{1}, {0}},
+			expected: []*nonogram.ClueError{
+				{Index: -1, Reason: nonogram.ErrFillMismatch},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := nonogram.ValidatePuzzle(tt.rows, tt.columns)
+			if tt.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *nonogram.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			require.Equal(t, tt.expected, validationErr.Problems)
+
+			var s nonogram.Solver
+			require.Equal(t, err, s.Solve(tt.rows, tt.columns))
+		})
+	}
+}
+
+func TestValidationErrorString(t *testing.T) {
+	err := nonogram.ValidatePuzzle(nonogram.FillPattern{{4}, {1}}, nonogram.FillPattern{{1}, {1}, {0, 1}})
+	require.EqualError(t, err, "invalid clues: row 0 [4]: blocks with gaps between them are longer than the line; "+
+		"column 2 [0 1]: zero block is mixed with other blocks")
+}